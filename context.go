@@ -0,0 +1,18 @@
+package logi
+
+import (
+	"context"
+
+	"github.com/lohvht/logi/iface"
+)
+
+// RegisterContextExtractor registers an extractor that pulls key-value pairs
+// out of a context.Context, e.g. request IDs or trace IDs. The extracted pairs
+// are attached to every logger returned by Logger.WithContext.
+func RegisterContextExtractor(e iface.ContextExtractor) { iface.RegisterContextExtractor(e) }
+
+// ContextWithFields returns a copy of ctx that carries the given key-value
+// pairs. Loggers returned by Logger.WithContext(ctx) will include them.
+func ContextWithFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	return iface.ContextWithFields(ctx, keysAndValues...)
+}
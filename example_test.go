@@ -1,11 +1,22 @@
 package logi_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/lohvht/logfeller"
+	"github.com/lohvht/logi"
 	"github.com/lohvht/logi/zaplogi"
+	"gopkg.in/natefinch/lumberjack.v2"
 	"gopkg.in/yaml.v2"
 )
 
@@ -218,3 +229,977 @@ log-file-configs:
 	// true
 	// 30
 }
+
+// printLogLines prints the console encoded log lines in buf without the
+// timestamp and caller, which vary from run to run.
+func printLogLines(buf *bytes.Buffer) {
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var cols []string
+		for i, col := range strings.Split(line, "\t") {
+			if i == 0 || strings.Contains(col, ".go:") {
+				continue
+			}
+			cols = append(cols, col)
+		}
+		fmt.Println(strings.Join(cols, " "))
+	}
+}
+
+func ExampleLogger_WithContext() {
+	logi.RegisterContextExtractor(func(ctx context.Context) []interface{} {
+		if userID, ok := ctx.Value(userIDKey{}).(string); ok {
+			return []interface{}{"user_id", userID}
+		}
+		return nil
+	})
+	var buf bytes.Buffer
+	logger, err := zaplogi.NewWithConfig(zaplogi.LogConfig{
+		LogFileConfigs: []zaplogi.LogFileConfig{
+			{LogRange: [2]zaplogi.Level{zaplogi.InfoLevel, zaplogi.MaxLevel}, Writer: &buf},
+		},
+	})
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	ctx := logi.ContextWithFields(context.Background(), "request_id", "abc123")
+	ctx = context.WithValue(ctx, userIDKey{}, "user-1")
+	logger.WithContext(ctx).Info("handling request", "path", "/healthz")
+	printLogLines(&buf)
+	// Output:
+	// INFO handling request {"request_id": "abc123", "user_id": "user-1", "path": "/healthz"}
+}
+
+type userIDKey struct{}
+
+func ExampleLogger_Sink() {
+	var buf bytes.Buffer
+	logger, err := zaplogi.NewWithConfig(zaplogi.LogConfig{
+		LogFileConfigs: []zaplogi.LogFileConfig{
+			{LogRange: [2]zaplogi.Level{zaplogi.InfoLevel, zaplogi.MaxLevel}, Writer: &buf},
+		},
+	})
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	logger.Debug("dropped")
+	sink, _ := logger.Sink("file[0]")
+	fmt.Println(sink.Level)
+	err = sink.Level.SetLogRange([2]zaplogi.Level{zaplogi.DebugLevel, zaplogi.MaxLevel})
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	fmt.Println(sink.Level)
+	logger.Debug("logged")
+	printLogLines(&buf)
+	// Output:
+	// [info fatal]
+	// [debug fatal]
+	// DEBUG logged
+}
+
+// printJSONLogLines prints the JSON encoded log lines in buf without the
+// timestamp and caller, which vary from run to run.
+func printJSONLogLines(buf *bytes.Buffer) {
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			return
+		}
+		delete(entry, "timestamp")
+		delete(entry, "caller")
+		b, _ := json.Marshal(entry)
+		fmt.Println(string(b))
+	}
+}
+
+func ExampleLogFileConfig_encoding() {
+	b := []byte(`log-file-configs:
+- log-range: ['info', 'fatal']
+  encoding: json
+`)
+	var logConfig zaplogi.LogConfig
+	err := yaml.Unmarshal(b, &logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	fmt.Println(logConfig.LogFileConfigs[0].Encoding)
+	var buf bytes.Buffer
+	logConfig.LogFileConfigs[0].Writer = &buf
+	logger, err := zaplogi.NewWithConfig(logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	logger.Named("http").Info("request served", "status", 200)
+	printJSONLogLines(&buf)
+	// Output:
+	// json
+	// {"level":"INFO","logger":"http","msg":"request served","status":200}
+}
+
+func ExampleEncoderConfig() {
+	b := []byte(`{
+		"encoder_config": {
+			"time_key": "-",
+			"caller_format": "disabled",
+			"level_format": "lower"
+		},
+		"log_file_configs": [
+			{
+				"log_range": ["info", "fatal"],
+				"encoding": "json",
+				"encoder_config": {
+					"message_key": "message",
+					"duration_format": "string"
+				}
+			}
+		]
+	}`)
+	var logConfig zaplogi.LogConfig
+	err := json.Unmarshal(b, &logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	jsonEncConf, _ := json.Marshal(logConfig.LogFileConfigs[0].EncoderConfig)
+	fmt.Println(string(jsonEncConf))
+	yamlEncConf, _ := yaml.Marshal(logConfig.EncoderConfig)
+	fmt.Print(string(yamlEncConf))
+
+	var buf bytes.Buffer
+	logConfig.LogFileConfigs[0].Writer = &buf
+	logger, err := zaplogi.NewWithConfig(logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	logger.Info("request served", "took", 1500*time.Millisecond)
+	fmt.Print(buf.String())
+	// Output:
+	// {"message_key":"message","duration_format":"string"}
+	// time-key: '-'
+	// level-format: lower
+	// caller-format: disabled
+	// {"level":"info","message":"request served","took":"1.5s"}
+}
+
+func ExampleLogConfig_tomlUnmarshal_logfeller() {
+	b := []byte(`console_log = true
+root_caller_skip = 1
+
+[[log_file_configs]]
+log_range = ["info", "fatal"]
+type = "lf"
+[log_file_configs.file_handler]
+filename = "info.log"
+when = "d"
+rotation_schedule = ["0000:00"]
+use_local = true
+backups = 30
+
+[[log_file_configs]]
+log_range = ["warn", "max"]
+type = "logfeller"
+[log_file_configs.file_handler]
+filename = "error.log"
+when = "d"
+rotation_schedule = ["0000:00"]
+use_local = true
+backups = 30
+
+[[log_file_configs]]
+logger_name = "db"
+log_range = ["debug", "fatal"]
+type = "Logfeller"
+[log_file_configs.file_handler]
+filename = "db.log"
+when = "d"
+rotation_schedule = ["0000:00"]
+use_local = true
+backups = 30
+
+[[log_file_configs]]
+logger_name = "data"
+type = "lf"
+[log_file_configs.file_handler]
+filename = "data.log"
+when = "d"
+rotation_schedule = ["0000:00"]
+use_local = true
+backups = 30
+`)
+	var logConfig zaplogi.LogConfig
+	err := toml.Unmarshal(b, &logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	fmt.Println(logConfig.ConsoleLog)
+	fmt.Println(logConfig.RootCallerSkip)
+	for i, rc := range logConfig.LogFileConfigs {
+		fmt.Printf("==================%d of %d==================\n", i+1, len(logConfig.LogFileConfigs))
+		fmt.Println(rc.LoggerName)
+		fmt.Println(rc.LogRange)
+		fmt.Println(rc.Type)
+		fmt.Println(rc.Writer.(*logfeller.File).Filename)
+		fmt.Println(rc.Writer.(*logfeller.File).When)
+		fmt.Println(rc.Writer.(*logfeller.File).RotationSchedule)
+		fmt.Println(rc.Writer.(*logfeller.File).UseLocal)
+		fmt.Println(rc.Writer.(*logfeller.File).Backups)
+	}
+	// Output:
+	// true
+	// 1
+	// ==================1 of 4==================
+	//
+	// [info fatal]
+	// logfeller
+	// info.log
+	// d
+	// [0000:00]
+	// true
+	// 30
+	// ==================2 of 4==================
+	//
+	// [warn fatal]
+	// logfeller
+	// error.log
+	// d
+	// [0000:00]
+	// true
+	// 30
+	// ==================3 of 4==================
+	// db
+	// [debug fatal]
+	// logfeller
+	// db.log
+	// d
+	// [0000:00]
+	// true
+	// 30
+	// ==================4 of 4==================
+	// data
+	// [info info]
+	// logfeller
+	// data.log
+	// d
+	// [0000:00]
+	// true
+	// 30
+}
+
+func ExampleLogConfig_tomlUnmarshal_lumberjack() {
+	b := []byte(`console_log = true
+
+[encoder_config]
+time_format = "rfc3339"
+
+[[log_file_configs]]
+log_range = ["info", "fatal"]
+type = "lumberjack"
+encoding = "json"
+[log_file_configs.file_handler]
+filename = "info.log"
+maxsize = 100
+maxage = 7
+maxbackups = 3
+localtime = true
+compress = true
+`)
+	var logConfig zaplogi.LogConfig
+	err := toml.Unmarshal(b, &logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	fmt.Println(logConfig.ConsoleLog)
+	fmt.Println(logConfig.EncoderConfig.TimeFormat)
+	rc := logConfig.LogFileConfigs[0]
+	fmt.Println(rc.LogRange)
+	fmt.Println(rc.Type)
+	fmt.Println(rc.Encoding)
+	fmt.Println(rc.Writer.(*lumberjack.Logger).Filename)
+	fmt.Println(rc.Writer.(*lumberjack.Logger).MaxSize)
+	fmt.Println(rc.Writer.(*lumberjack.Logger).MaxAge)
+	fmt.Println(rc.Writer.(*lumberjack.Logger).MaxBackups)
+	fmt.Println(rc.Writer.(*lumberjack.Logger).LocalTime)
+	fmt.Println(rc.Writer.(*lumberjack.Logger).Compress)
+	// Output:
+	// true
+	// rfc3339
+	// [info fatal]
+	// lumberjack
+	// json
+	// info.log
+	// 100
+	// 7
+	// 3
+	// true
+	// true
+}
+
+func ExampleLogConfig_marshal() {
+	logConfig := zaplogi.LogConfig{
+		ConsoleLog: true,
+		LogFileConfigs: []zaplogi.LogFileConfig{
+			{
+				LogRange: [2]zaplogi.Level{zaplogi.InfoLevel, zaplogi.MaxLevel},
+				Encoding: zaplogi.JSONEncoding,
+				Writer: &logfeller.File{
+					Filename:         "info.log",
+					When:             "d",
+					RotationSchedule: []string{"0000:00"},
+					UseLocal:         true,
+					Backups:          30,
+				},
+			},
+			{
+				LoggerName: "db",
+				LogRange:   [2]zaplogi.Level{zaplogi.DebugLevel, zaplogi.MaxLevel},
+				Type:       zaplogi.Lumberjack,
+				Writer:     &lumberjack.Logger{Filename: "db.log", MaxSize: 100},
+			},
+		},
+	}
+	b, err := json.MarshalIndent(logConfig, "", "  ")
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	fmt.Println(string(b))
+
+	b, err = yaml.Marshal(logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	var roundTripped zaplogi.LogConfig
+	err = yaml.Unmarshal(b, &roundTripped)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	fmt.Println(roundTripped.LogFileConfigs[0].Type, roundTripped.LogFileConfigs[0].Writer.(*logfeller.File).Filename)
+	fmt.Println(roundTripped.LogFileConfigs[1].Type, roundTripped.LogFileConfigs[1].Writer.(*lumberjack.Logger).Filename)
+	// Output:
+	// {
+	//   "console_log": true,
+	//   "console_log_encoding": "console",
+	//   "root_caller_skip": 0,
+	//   "encoder_config": {},
+	//   "log_file_configs": [
+	//     {
+	//       "logger_name": "",
+	//       "log_range": [
+	//         "info",
+	//         "fatal"
+	//       ],
+	//       "type": "logfeller",
+	//       "encoding": "json",
+	//       "encoder_config": {},
+	//       "file_handler": {
+	//         "filename": "info.log",
+	//         "when": "d",
+	//         "rotation_schedule": [
+	//           "0000:00"
+	//         ],
+	//         "use_local": true,
+	//         "backups": 30,
+	//         "backup_time_format": ""
+	//       }
+	//     },
+	//     {
+	//       "logger_name": "db",
+	//       "log_range": [
+	//         "debug",
+	//         "fatal"
+	//       ],
+	//       "type": "lumberjack",
+	//       "encoding": "console",
+	//       "encoder_config": {},
+	//       "file_handler": {
+	//         "filename": "db.log",
+	//         "maxsize": 100,
+	//         "maxage": 0,
+	//         "maxbackups": 0,
+	//         "localtime": false,
+	//         "compress": false
+	//       }
+	//     }
+	//   ]
+	// }
+	// logfeller info.log
+	// lumberjack db.log
+}
+
+// memoryWriter is an in-house writer type that keeps written entries in memory.
+type memoryWriter struct {
+	Name string `json:"name" yaml:"name"`
+	bytes.Buffer
+}
+
+var memoryWriterType, _ = zaplogi.RegisterWriterType(func() io.Writer { return &memoryWriter{} }, "memory", "mem")
+
+func ExampleRegisterWriterType() {
+	b := []byte(`log-file-configs:
+- log-range: ['info', 'fatal']
+  type: MEM
+  file-handler:
+    name: 'audit'
+`)
+	var logConfig zaplogi.LogConfig
+	err := yaml.Unmarshal(b, &logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	rc := logConfig.LogFileConfigs[0]
+	fmt.Println(rc.Type, rc.Type == memoryWriterType)
+	fmt.Println(rc.Writer.(*memoryWriter).Name)
+
+	rc.Type = zaplogi.NoWriter
+	jsonConf, err := json.Marshal(rc)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	fmt.Println(string(jsonConf))
+	// Output:
+	// memory true
+	// audit
+	// {"logger_name":"","log_range":["info","fatal"],"type":"memory","encoding":"console","encoder_config":{},"file_handler":{"name":"audit"}}
+}
+
+func ExampleLogConfig_yamlUnmarshal_file() {
+	dir, err := os.MkdirTemp("", "logi")
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	b := []byte(`encoder-config:
+  time-key: '-'
+  caller-format: disabled
+log-file-configs:
+- log-range: ['info', 'fatal']
+  type: file
+  file-handler:
+    filename: '` + filepath.Join(dir, "logs", "info.log") + `'
+    perm: '0600'
+    mkdir-parents: yes
+- log-range: ['warn', 'fatal']
+  type: stdout
+`)
+	var logConfig zaplogi.LogConfig
+	err = yaml.Unmarshal(b, &logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	for _, rc := range logConfig.LogFileConfigs {
+		fmt.Println(rc.Type)
+	}
+	f := logConfig.LogFileConfigs[0].Writer.(*zaplogi.File)
+	fmt.Println(filepath.Base(f.Filename), os.FileMode(f.Perm), f.Truncate, f.MkdirParents)
+
+	logger, err := zaplogi.NewWithConfig(logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	logger.Info("to file only")
+	logger.Warn("to file and stdout")
+	if err := f.Close(); err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	content, err := os.ReadFile(f.Filename)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	fmt.Print(string(content))
+	// Output:
+	// file
+	// stdout
+	// info.log -rw------- false true
+	// WARN	to file and stdout
+	// INFO	to file only
+	// WARN	to file and stdout
+}
+
+func ExampleShutdown() {
+	dir, err := os.MkdirTemp("", "logi")
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	f := &zaplogi.File{Filename: filepath.Join(dir, "info.log")}
+	logger, err := zaplogi.NewWithConfig(zaplogi.LogConfig{
+		EncoderConfig: zaplogi.EncoderConfig{TimeKey: zaplogi.OmitKey, CallerFormat: zaplogi.DisabledCallerFormat},
+		LogFileConfigs: []zaplogi.LogFileConfig{
+			{LogRange: [2]zaplogi.Level{zaplogi.InfoLevel, zaplogi.MaxLevel}, Writer: f},
+		},
+	})
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	prev := logi.Get()
+	defer logi.SetDefault(prev)
+	logi.SetDefault(logger)
+
+	logi.Get().Info("shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := logi.Shutdown(ctx); err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	content, err := os.ReadFile(f.Filename)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	fmt.Print(string(content))
+	// Output:
+	// INFO	shutting down
+}
+
+func ExampleInfo() {
+	var buf bytes.Buffer
+	logger, err := zaplogi.NewWithConfig(zaplogi.LogConfig{
+		RootCallerSkip: 1,
+		EncoderConfig:  zaplogi.EncoderConfig{TimeKey: zaplogi.OmitKey},
+		LogFileConfigs: []zaplogi.LogFileConfig{
+			{LogRange: [2]zaplogi.Level{zaplogi.InfoLevel, zaplogi.MaxLevel}, Writer: &buf},
+		},
+	})
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	restore, _ := logi.ReplaceDefault(logger)
+	defer restore()
+
+	logi.Info("package level", "key", "value")
+	logi.Named("db").Warnf("%d slow queries", 2)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		// drop the caller's directory and line number
+		fmt.Println(regexp.MustCompile(`\S*/(\S+\.go):\d+`).ReplaceAllString(line, "$1"))
+	}
+	// Output:
+	// INFO	example_test.go	package level	{"key": "value"}
+	// WARN	db	example_test.go	2 slow queries
+}
+
+func ExampleLogFileConfig_loggerName() {
+	var dbBuf, poolBuf, restBuf bytes.Buffer
+	logger, err := zaplogi.NewWithConfig(zaplogi.LogConfig{
+		EncoderConfig: zaplogi.EncoderConfig{TimeKey: zaplogi.OmitKey, CallerFormat: zaplogi.DisabledCallerFormat},
+		LoggerLevels:  map[string]zaplogi.Level{"": zaplogi.InfoLevel, "db": zaplogi.WarnLevel, "db.pool.conn": zaplogi.DebugLevel},
+		LogFileConfigs: []zaplogi.LogFileConfig{
+			{LoggerName: "db", LogRange: [2]zaplogi.Level{zaplogi.DebugLevel, zaplogi.MaxLevel}, Writer: &dbBuf},
+			{LoggerName: "db.pool", LogRange: [2]zaplogi.Level{zaplogi.DebugLevel, zaplogi.MaxLevel}, Writer: &poolBuf},
+			{LogRange: [2]zaplogi.Level{zaplogi.DebugLevel, zaplogi.MaxLevel}, Writer: &restBuf},
+		},
+	})
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	db := logger.Named("db")
+	db.Info("dropped by the db logger level")
+	db.Warn("to db")
+	db.Named("migrations").Error("to db")
+	db.Named("pool").Warn("to db.pool")
+	db.Named("pool").Named("conn").Debug("to db.pool")
+	logger.Named("dbx").Debug("dropped by the root logger level")
+	logger.Named("dbx").Info("to the rest")
+	logger.Debug("dropped by the root logger level")
+	logger.Info("to the rest")
+	fmt.Print("db:\n", dbBuf.String(), "db.pool:\n", poolBuf.String(), "rest:\n", restBuf.String())
+	// Output:
+	// db:
+	// WARN	db	to db
+	// ERROR	db.migrations	to db
+	// db.pool:
+	// WARN	db.pool	to db.pool
+	// DEBUG	db.pool.conn	to db.pool
+	// rest:
+	// INFO	dbx	to the rest
+	// INFO	to the rest
+}
+
+func ExampleLogFileConfig_loggerNames() {
+	b := []byte(`{
+		"encoder_config": {"time_key": "-", "caller_format": "disabled"},
+		"log_file_configs": [
+			{
+				"log_range": ["debug", "fatal"],
+				"logger_names": ["grpc.*", "/(http|rpc)\\.client/"],
+				"exclude_logger_names": ["grpc.reflection"]
+			},
+			{"logger_name": "grpc.health", "log_range": ["debug", "fatal"]},
+			{"log_range": ["debug", "fatal"]}
+		]
+	}`)
+	var logConfig zaplogi.LogConfig
+	err := json.Unmarshal(b, &logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	var grpcBuf, healthBuf, restBuf bytes.Buffer
+	logConfig.LogFileConfigs[0].Writer = &grpcBuf
+	logConfig.LogFileConfigs[1].Writer = &healthBuf
+	logConfig.LogFileConfigs[2].Writer = &restBuf
+	logger, err := zaplogi.NewWithConfig(logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	for _, name := range []string{"grpc.server", "grpc.server.stream", "grpc.health", "grpc.reflection", "grpc", "http.client", "http.client.pool", "rpc.client"} {
+		logger.Named(name).Info("hello")
+	}
+	fmt.Print("grpc:\n", grpcBuf.String(), "grpc.health:\n", healthBuf.String(), "rest:\n", restBuf.String())
+	// Output:
+	// grpc:
+	// INFO	grpc.server	hello
+	// INFO	grpc.server.stream	hello
+	// INFO	http.client	hello
+	// INFO	rpc.client	hello
+	// grpc.health:
+	// INFO	grpc.health	hello
+	// rest:
+	// INFO	grpc.reflection	hello
+	// INFO	grpc	hello
+	// INFO	http.client.pool	hello
+}
+
+func ExampleConsoleConfig() {
+	b := []byte(`encoder-config:
+  time-key: '-'
+  caller-format: disabled
+console:
+  stream: stdout
+  log-range: ['info', 'fatal']
+  colour: 'off'
+  encoding: json
+`)
+	var logConfig zaplogi.LogConfig
+	err := yaml.Unmarshal(b, &logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	cc := logConfig.Console
+	fmt.Println(cc.Stream, cc.SplitLevel, cc.LogRange, cc.Colour, cc.Encoding, cc.Development)
+
+	logger, err := zaplogi.NewWithConfig(logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	logger.Debug("below the console log range")
+	logger.Info("to stdout")
+	logger.Error("to stdout as well", "key", "value")
+	// Output:
+	// stdout warn [info fatal] off json false
+	// {"level":"INFO","msg":"to stdout"}
+	// {"level":"ERROR","msg":"to stdout as well","key":"value"}
+}
+
+func ExampleSamplingConfig() {
+	var sampledBuf, dedupBuf bytes.Buffer
+	b := []byte(`{
+		"encoder_config": {"time_key": "-", "caller_format": "disabled"},
+		"sampling": {"initial": 2, "thereafter": 3, "tick": "1m"},
+		"log_file_configs": [
+			{"log_range": ["info", "fatal"]},
+			{"log_range": ["info", "fatal"], "sampling": {"dedup": true}}
+		]
+	}`)
+	var logConfig zaplogi.LogConfig
+	err := json.Unmarshal(b, &logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	logConfig.LogFileConfigs[0].Writer = &sampledBuf
+	logConfig.LogFileConfigs[1].Writer = &dedupBuf
+	logger, err := zaplogi.NewWithConfig(logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	for i := 1; i <= 8; i++ {
+		logger.Warn("disk almost full", "attempt", i)
+	}
+	for i := 0; i < 4; i++ {
+		logger.Warn("disk full")
+	}
+	logger.Info("cleaning up")
+	logger.Warn("disk full")
+	logger.Warn("disk full")
+	if err := logger.Sync(); err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	fmt.Print("sampled:\n", sampledBuf.String(), "dedup:\n", dedupBuf.String())
+	// Output:
+	// sampled:
+	// WARN	disk almost full	{"attempt": 1}
+	// WARN	disk almost full	{"attempt": 2}
+	// WARN	disk almost full	{"attempt": 5}
+	// WARN	disk almost full	{"attempt": 8}
+	// WARN	disk full
+	// WARN	disk full
+	// INFO	cleaning up
+	// WARN	disk full
+	// dedup:
+	// WARN	disk almost full	{"attempt": 1}
+	// WARN	disk almost full	{"attempt": 2}
+	// WARN	disk almost full	{"attempt": 3}
+	// WARN	disk almost full	{"attempt": 4}
+	// WARN	disk almost full	{"attempt": 5}
+	// WARN	disk almost full	{"attempt": 6}
+	// WARN	disk almost full	{"attempt": 7}
+	// WARN	disk almost full	{"attempt": 8}
+	// WARN	disk full
+	// WARN	disk full	{"repeated": 3}
+	// INFO	cleaning up
+	// WARN	disk full
+	// WARN	disk full	{"repeated": 1}
+}
+
+func ExampleRateLimitConfig() {
+	b := []byte(`encoder-config:
+  time-key: '-'
+  caller-format: disabled
+log-file-configs:
+- log-range: ['info', 'fatal']
+  rate-limit:
+    entries: 2
+    report-interval: 1m
+`)
+	var logConfig zaplogi.LogConfig
+	err := yaml.Unmarshal(b, &logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	var buf bytes.Buffer
+	logConfig.LogFileConfigs[0].Writer = &buf
+	logger, err := zaplogi.NewWithConfig(logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	for i := 1; i <= 5; i++ {
+		logger.Error("connection refused", "attempt", i)
+	}
+	if err := logger.Sync(); err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	fmt.Print(buf.String())
+	// Output:
+	// ERROR	connection refused	{"attempt": 1}
+	// ERROR	connection refused	{"attempt": 2}
+	// WARN	dropped 3 entries	{"dropped": 3}
+}
+
+func ExampleAsyncConfig() {
+	b := []byte(`{
+		"encoder_config": {"time_key": "-", "caller_format": "disabled"},
+		"log_file_configs": [
+			{
+				"log_range": ["info", "fatal"],
+				"async": {"queue_size": 256, "overflow": "drop_oldest", "flush_interval": "100ms", "flush_size": 4096}
+			}
+		]
+	}`)
+	var logConfig zaplogi.LogConfig
+	err := json.Unmarshal(b, &logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	var buf bytes.Buffer
+	logConfig.LogFileConfigs[0].Writer = &buf
+	logger, err := zaplogi.NewWithConfig(logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	defer logger.Close()
+	logger.Info("written in the background")
+	// Sync waits for the queued entries to be written.
+	if err := logger.Sync(); err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	fmt.Print(buf.String())
+	sink, _ := logger.Sink("file[0]")
+	fmt.Printf("%+v\n", sink.Async.Stats())
+	// Output:
+	// INFO	written in the background
+	// {QueueDepth:0 QueueSize:256 Dropped:0}
+}
+
+type user struct {
+	Name     string
+	Password string
+}
+
+// Redact implements zaplogi.Redactor.
+func (u user) Redact() interface{} {
+	return user{Name: u.Name, Password: "***"}
+}
+
+func ExampleRedactionConfig() {
+	b := []byte(`{
+		"encoder_config": {"time_key": "-", "caller_format": "disabled"},
+		"redaction": {
+			"keys": ["*password*", "token"],
+			"values": ["secret-[0-9]+"],
+			"patterns": ["email", "pan"]
+		},
+		"log_file_configs": [{"log_range": ["info", "fatal"]}]
+	}`)
+	var logConfig zaplogi.LogConfig
+	err := json.Unmarshal(b, &logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	var buf bytes.Buffer
+	logConfig.LogFileConfigs[0].Writer = &buf
+	logger, err := zaplogi.NewWithConfig(logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	logger.With("Token", "abc").Info("login", "db_password", "hunter2", "user", user{Name: "alice", Password: "hunter2"})
+	logger.Error("payment failed", "card", "4111 1111 1111 1111", "note", "contact bob@example.com about secret-42")
+	fmt.Print(buf.String())
+	// Output:
+	// INFO	login	{"Token": "[REDACTED]", "db_password": "[REDACTED]", "user": {"Name":"alice","Password":"***"}}
+	// ERROR	payment failed	{"card": "[REDACTED]", "note": "contact [REDACTED] about [REDACTED]"}
+}
+
+func ExampleNewDiscard() {
+	logger := zaplogi.NewDiscard()
+	logger.With("key", "value").Error("discarded")
+	defer func() {
+		fmt.Println("recovered:", recover())
+	}()
+	logger.Panic("still panics")
+	// Output:
+	// recovered: still panics
+}
+
+func ExampleLazy() {
+	var buf bytes.Buffer
+	logger, err := zaplogi.NewWithConfig(zaplogi.LogConfig{
+		EncoderConfig: zaplogi.EncoderConfig{TimeKey: zaplogi.OmitKey, CallerFormat: zaplogi.DisabledCallerFormat},
+		LogFileConfigs: []zaplogi.LogFileConfig{
+			{LogRange: [2]zaplogi.Level{zaplogi.InfoLevel, zaplogi.MaxLevel}, Writer: &buf},
+		},
+	})
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	dump := func() interface{} {
+		fmt.Println("computing dump")
+		return "42 rows"
+	}
+	fmt.Println("debug enabled:", logger.Enabled(zaplogi.DebugLevel))
+	logger.Debug("state", "dump", logi.Lazy(dump))
+	logger.Info("state", "dump", logi.Lazy(dump))
+	fmt.Print(buf.String())
+	// Output:
+	// debug enabled: false
+	// computing dump
+	// INFO	state	{"dump": "42 rows"}
+}
+
+func ExampleLogger_Enabled() {
+	logger, err := zaplogi.NewWithConfig(zaplogi.LogConfig{
+		LoggerLevels: map[string]zaplogi.Level{"db": zaplogi.WarnLevel},
+		LogFileConfigs: []zaplogi.LogFileConfig{
+			{LoggerName: "http", LogRange: [2]zaplogi.Level{zaplogi.DebugLevel, zaplogi.MaxLevel}, Writer: io.Discard},
+			{LogRange: [2]zaplogi.Level{zaplogi.InfoLevel, zaplogi.MaxLevel}, Writer: io.Discard},
+		},
+	})
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	fmt.Println(logger.Enabled(zaplogi.DebugLevel))
+	fmt.Println(logger.Named("http").Enabled(zaplogi.DebugLevel))
+	fmt.Println(logger.Named("db").Enabled(zaplogi.InfoLevel))
+	fmt.Println(logger.Named("db").Enabled(zaplogi.ErrorLevel))
+	// Output:
+	// false
+	// true
+	// false
+	// true
+}
+
+// criticalLevel is a custom level between error and dpanic.
+var criticalLevel, _ = zaplogi.RegisterLevel("critical", 25)
+
+func ExampleRegisterLevel() {
+	b := []byte(`{
+		"root_caller_skip": 1,
+		"encoder_config": {"time_key": "-"},
+		"log_file_configs": [
+			{"log_range": ["trace", "fatal"]},
+			{"log_range": ["notice", "critical"], "encoding": "json", "encoder_config": {"caller_format": "disabled"}}
+		]
+	}`)
+	var logConfig zaplogi.LogConfig
+	err := json.Unmarshal(b, &logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	var consoleBuf, jsonBuf bytes.Buffer
+	logConfig.LogFileConfigs[0].Writer = &consoleBuf
+	logConfig.LogFileConfigs[1].Writer = &jsonBuf
+	logger, err := zaplogi.NewWithConfig(logConfig)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	logger.Log(zaplogi.TraceLevel, "entering handler", "id", 1)
+	logger.Info("handling")
+	logger.Logf(zaplogi.NoticeLevel, "%d retries", 3)
+	logger.Log(criticalLevel, "disk failing", "disk", "sda")
+	logger.Log(zaplogi.ErrorLevel, "request failed")
+	fmt.Println(zaplogi.NoticeLevel.AtLeast(zaplogi.InfoLevel), zaplogi.NoticeLevel.AtLeast(zaplogi.WarnLevel))
+	for _, line := range strings.Split(strings.TrimSpace(consoleBuf.String()), "\n") {
+		// drop the caller's directory and line number
+		fmt.Println(regexp.MustCompile(`\S*/(\S+\.go):\d+`).ReplaceAllString(line, "$1"))
+	}
+	fmt.Print(jsonBuf.String())
+	// Output:
+	// true false
+	// TRACE	example_test.go	entering handler	{"id": 1}
+	// INFO	example_test.go	handling
+	// NOTICE	example_test.go	3 retries
+	// CRITICAL	example_test.go	disk failing	{"disk": "sda"}
+	// ERROR	example_test.go	request failed
+	// {"level":"NOTICE","msg":"3 retries"}
+	// {"level":"CRITICAL","msg":"disk failing","disk":"sda"}
+	// {"level":"ERROR","msg":"request failed"}
+}
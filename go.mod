@@ -1,17 +1,15 @@
 module github.com/lohvht/logi
 
-go 1.19
+go 1.21
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/lohvht/logfeller v1.0.0
 	github.com/pkg/errors v0.9.1
+	go.uber.org/multierr v1.9.0
 	go.uber.org/zap v1.24.0
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0
 	gopkg.in/yaml.v2 v2.4.0
 )
 
-require (
-	github.com/BurntSushi/toml v1.2.1 // indirect
-	go.uber.org/atomic v1.10.0 // indirect
-	go.uber.org/multierr v1.9.0 // indirect
-)
+require go.uber.org/atomic v1.10.0 // indirect
@@ -0,0 +1,64 @@
+package iface
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextExtractor extracts key-value pairs from a context.Context. The
+// returned slice should be in the same key-value order as With.
+type ContextExtractor func(ctx context.Context) []interface{}
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   []ContextExtractor
+)
+
+// RegisterContextExtractor registers an extractor that is run every time
+// Logger.WithContext is called. Extractors are run in the order that they are
+// registered, after the fields stored via ContextWithFields.
+func RegisterContextExtractor(e ContextExtractor) {
+	if e == nil {
+		return
+	}
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(extractors, e)
+}
+
+type contextFieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx that carries the given key-value
+// pairs. Any fields already stored in ctx are kept, and the new pairs are
+// appended after them.
+func ContextWithFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	if len(keysAndValues) == 0 {
+		return ctx
+	}
+	existing, _ := ctx.Value(contextFieldsKey{}).([]interface{})
+	fields := make([]interface{}, 0, len(existing)+len(keysAndValues))
+	fields = append(fields, existing...)
+	fields = append(fields, keysAndValues...)
+	return context.WithValue(ctx, contextFieldsKey{}, fields)
+}
+
+// ExtractContext returns the key-value pairs stored in ctx via
+// ContextWithFields, followed by the key-value pairs returned by each
+// registered ContextExtractor. Logger implementations should use this in
+// WithContext.
+func ExtractContext(ctx context.Context) []interface{} {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(contextFieldsKey{}).([]interface{})
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	if len(extractors) == 0 {
+		return fields
+	}
+	kvs := append([]interface{}(nil), fields...)
+	for _, e := range extractors {
+		kvs = append(kvs, e(ctx)...)
+	}
+	return kvs
+}
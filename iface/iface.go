@@ -1,5 +1,7 @@
 package iface
 
+import "context"
+
 // Logger is the standard interface for loggers in toc-common. As long as your
 // logging framework satisfies this interface and its contract, you should
 // replace the default implementation of this Logger to your desired format via
@@ -29,6 +31,14 @@ type Logger interface {
 	Error(msg string, keysAndValues ...interface{})
 	// Errorf uses fmt.Sprintf to log a templated message in error level.
 	Errorf(template string, args ...interface{})
+	// DPanic logs a message with some additional context in dpanic level. If
+	// the logger is in development mode, it then panics. The variadic
+	// key-value pairs are treated as they are in With. See With for more
+	// information
+	DPanic(msg string, keysAndValues ...interface{})
+	// DPanicf uses fmt.Sprintf to log a templated message in dpanic level. If
+	// the logger is in development mode, it then panics.
+	DPanicf(template string, args ...interface{})
 	// Panic logs a message with some additional context in panic level and then
 	// proceeds to panic. The variadic key-value pairs are treated as they are in
 	// With. See With for more information
@@ -44,6 +54,21 @@ type Logger interface {
 	// exits.
 	Fatalf(template string, args ...interface{})
 
+	// Log logs a message with some additional context in the given level,
+	// which may be a custom level. Logging in dpanic, panic or fatal level
+	// behaves like DPanic, Panic and Fatal respectively. The variadic
+	// key-value pairs are treated as they are in With. See With for more
+	// information
+	Log(level Level, msg string, keysAndValues ...interface{})
+	// Logf uses fmt.Sprintf to log a templated message in the given level,
+	// which may be a custom level. See Log for more information.
+	Logf(level Level, template string, args ...interface{})
+
+	// Enabled returns true if the logger logs entries at the given level.
+	// This allows skipping the building of expensive key-value pairs that
+	// would be dropped anyway.
+	Enabled(level Level) bool
+
 	// With returns a logger that provides additional context to the logger.
 	// The arguments passed in should be should be in the order of a key-value
 	// pair. e.g.
@@ -51,9 +76,23 @@ type Logger interface {
 	// where arg1 and arg2 are the values that we are interested in.
 	With(args ...interface{}) Logger
 
+	// WithContext returns a logger that provides the key-value pairs extracted
+	// from ctx as additional context to the logger. See ExtractContext for how
+	// the key-value pairs are extracted.
+	WithContext(ctx context.Context) Logger
+
 	// Named returns a new logger with the given name
 	Named(loggerName string) Logger
 
 	// CallSkip overrides the default underlying callskips settings for the logger implementation
 	CallSkip(skips int) Logger
+
+	// Sync flushes any buffered log entries.
+	Sync() error
+	// Close flushes any buffered log entries and releases the underlying
+	// outputs, such as open files. Outputs are usually shared between a logger
+	// and the loggers derived from it via With, Named etc., so Close should
+	// only be called once the logger and all loggers derived from it are no
+	// longer in use.
+	Close() error
 }
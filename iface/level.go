@@ -0,0 +1,251 @@
+package iface
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// Level is the severity of a log entry. Levels share the values of
+// zapcore.Level, so a Level converts directly to and from a zapcore.Level.
+type Level int8
+
+// Log Levels, this section is taken directly from zapcore
+const (
+	// DebugLevel logs are typically voluminous, and are usually disabled in
+	// production.
+	DebugLevel Level = iota - 1
+	// InfoLevel is the default logging priority.
+	InfoLevel
+	// WarnLevel logs are more important than Info, but don't need individual
+	// human review.
+	WarnLevel
+	// ErrorLevel logs are high-priority. If an application is running smoothly,
+	// it shouldn't generate any error-level logs.
+	ErrorLevel
+	// DPanicLevel logs are particularly important errors. In development the
+	// logger panics after writing the message.
+	DPanicLevel
+	// PanicLevel logs a message, then panics.
+	PanicLevel
+	// FatalLevel logs a message, then calls os.Exit(1).
+	FatalLevel
+
+	MinLevel = DebugLevel
+	MaxLevel = FatalLevel
+)
+
+// Custom levels, which are predefined in addition to the levels above. See
+// RegisterLevel for how custom levels work.
+const (
+	// TraceLevel logs are more voluminous than Debug, and are usually only
+	// enabled when tracing a specific problem. Note that TraceLevel is below
+	// MinLevel.
+	TraceLevel Level = -2
+	// NoticeLevel logs are normal but significant events, more important than
+	// Info but less than Warn.
+	NoticeLevel Level = -3
+)
+
+// customLevel is a level registered via RegisterLevel.
+type customLevel struct {
+	name     string
+	severity int
+}
+
+// customLevels are the registered custom levels, which are allocated values
+// from -2 downwards so that zap treats them like levels below DPanic.
+var customLevels = struct {
+	sync.RWMutex
+	byLevel map[Level]customLevel
+	byName  map[string]Level
+	next    Level
+}{
+	byLevel: map[Level]customLevel{
+		TraceLevel:  {name: "trace", severity: -20},
+		NoticeLevel: {name: "notice", severity: 5},
+	},
+	byName: map[string]Level{"trace": TraceLevel, "notice": NoticeLevel},
+	next:   NoticeLevel - 1,
+}
+
+// RegisterLevel registers a custom level with the given lower-case name and
+// severity, returning the new level. The severities of the standard levels
+// are 10 times their values, i.e. -10 for DebugLevel, 0 for InfoLevel, 10 for
+// WarnLevel and so on, so a severity of 15 orders a level between WarnLevel
+// and ErrorLevel. Levels are ordered by their severities rather than their
+// values, see Severity.
+//
+// Once registered, the level may be unmarshalled from its name, and is
+// encoded with its name. Custom levels never panic or exit, nor are they
+// sampled. Register levels during initialisation, before they are used in
+// configurations.
+func RegisterLevel(name string, severity int) (Level, error) {
+	name = strings.ToLower(name)
+	var l Level
+	if name == "" || l.unmarshalText([]byte(name)) {
+		return 0, fmt.Errorf("level %q is already registered", name)
+	}
+	customLevels.Lock()
+	defer customLevels.Unlock()
+	if _, ok := customLevels.byName[name]; ok {
+		return 0, fmt.Errorf("level %q is already registered", name)
+	}
+	if customLevels.next > NoticeLevel {
+		// next has wrapped around after allocating math.MinInt8.
+		return 0, fmt.Errorf("too many levels registered, cannot register %q", name)
+	}
+	l = customLevels.next
+	customLevels.next--
+	customLevels.byLevel[l] = customLevel{name: name, severity: severity}
+	customLevels.byName[name] = l
+	sortCustomLevels()
+	return l, nil
+}
+
+// CustomLevels returns the registered custom levels, including TraceLevel
+// and NoticeLevel, ordered by severity. The returned slice is shared and must
+// not be modified; it is replaced by a new slice whenever a level is
+// registered.
+func CustomLevels() []Level { return *sortedCustomLevels.Load() }
+
+// sortedCustomLevels are the registered custom levels ordered by severity,
+// which are sorted when a level is registered rather than every time they are
+// needed.
+var sortedCustomLevels atomic.Pointer[[]Level]
+
+func init() { sortCustomLevels() }
+
+// sortCustomLevels updates sortedCustomLevels. customLevels must be locked.
+func sortCustomLevels() {
+	levels := make([]Level, 0, len(customLevels.byLevel))
+	for l := range customLevels.byLevel {
+		levels = append(levels, l)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		si, sj := customLevels.byLevel[levels[i]].severity, customLevels.byLevel[levels[j]].severity
+		return si < sj || (si == sj && levels[i] > levels[j])
+	})
+	sortedCustomLevels.Store(&levels)
+}
+
+// lookupCustomLevel returns the custom level registered as l.
+func lookupCustomLevel(l Level) (customLevel, bool) {
+	if l > TraceLevel {
+		return customLevel{}, false
+	}
+	customLevels.RLock()
+	defer customLevels.RUnlock()
+	cl, ok := customLevels.byLevel[l]
+	return cl, ok
+}
+
+// IsCustom returns true if the level is a registered custom level.
+func (l Level) IsCustom() bool {
+	_, ok := lookupCustomLevel(l)
+	return ok
+}
+
+// Severity returns the severity of the level, which orders levels. The
+// severities of the standard levels are 10 times their values, while custom
+// levels have the severities that they were registered with.
+func (l Level) Severity() int {
+	if cl, ok := lookupCustomLevel(l); ok {
+		return cl.severity
+	}
+	return 10 * int(l)
+}
+
+// AtLeast returns true if the level is at least as severe as other.
+func (l Level) AtLeast(other Level) bool {
+	if l >= DebugLevel && other >= DebugLevel {
+		// Neither level is custom, so the severities need not be looked up.
+		return l >= other
+	}
+	return l.Severity() >= other.Severity()
+}
+
+// MarshalText marshals the Level to text. Note that the text representation
+// drops the -Level suffix (see example).
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText unmarshals text to a level. Like MarshalText, UnmarshalText
+// expects the text representation of a Level to drop the -Level suffix (see
+// example).
+//
+// In particular, this makes it easy to configure logging levels using YAML,
+// TOML, or JSON files.
+func (l *Level) UnmarshalText(text []byte) error {
+	if l == nil {
+		return errors.New("can't unmarshal a nil *Level")
+	}
+	if !l.unmarshalText(text) && !l.unmarshalText(bytes.ToLower(text)) {
+		return fmt.Errorf("unrecognised level: %q", text)
+	}
+	return nil
+}
+
+func (l *Level) unmarshalText(text []byte) bool {
+	switch string(text) {
+	case "debug", "DEBUG", "min", "MIN":
+		*l = DebugLevel
+	case "info", "INFO", "": // make the zero value useful
+		*l = InfoLevel
+	case "warn", "WARN":
+		*l = WarnLevel
+	case "error", "ERROR":
+		*l = ErrorLevel
+	case "dpanic", "DPANIC":
+		*l = DPanicLevel
+	case "panic", "PANIC":
+		*l = PanicLevel
+	case "fatal", "FATAL", "max", "MAX":
+		*l = FatalLevel
+	default:
+		customLevels.RLock()
+		defer customLevels.RUnlock()
+		custom, ok := customLevels.byName[string(text)]
+		if !ok {
+			return false
+		}
+		*l = custom
+	}
+	return true
+}
+
+// String returns a lower-case ASCII representation of the log level.
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case DPanicLevel:
+		return "dpanic"
+	case PanicLevel:
+		return "panic"
+	case FatalLevel:
+		return "fatal"
+	default:
+		if cl, ok := lookupCustomLevel(l); ok {
+			return cl.name
+		}
+		return fmt.Sprintf("Level(%d)", l)
+	}
+}
+
+// CapitalString returns an all-caps ASCII representation of the log level.
+func (l Level) CapitalString() string {
+	return strings.ToUpper(l.String())
+}
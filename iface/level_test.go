@@ -0,0 +1,45 @@
+package iface
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegisterLevel(t *testing.T) {
+	verbose, err := RegisterLevel("Verbose", -15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verbose >= NoticeLevel {
+		t.Errorf("RegisterLevel() = %d, want a value below NoticeLevel", verbose)
+	}
+	if !verbose.IsCustom() || verbose.String() != "verbose" || verbose.CapitalString() != "VERBOSE" {
+		t.Errorf("got custom %v, name %q and %q", verbose.IsCustom(), verbose.String(), verbose.CapitalString())
+	}
+	var l Level
+	if err := l.UnmarshalText([]byte("VERBOSE")); err != nil || l != verbose {
+		t.Errorf("UnmarshalText(VERBOSE) = %d, %v, want %d", l, err, verbose)
+	}
+	if !verbose.AtLeast(TraceLevel) || verbose.AtLeast(DebugLevel) {
+		t.Error("verbose is not ordered between trace and debug")
+	}
+	if got, want := CustomLevels(), []Level{TraceLevel, verbose, NoticeLevel}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CustomLevels() = %v, want %v", got, want)
+	}
+	for _, name := range []string{"verbose", "info", "trace", "min", ""} {
+		if _, err := RegisterLevel(name, 0); err == nil {
+			t.Errorf("RegisterLevel(%q): got nil error for a registered name", name)
+		}
+	}
+}
+
+func TestLevelAtLeast(t *testing.T) {
+	ordered := []Level{TraceLevel, DebugLevel, InfoLevel, NoticeLevel, WarnLevel, ErrorLevel, DPanicLevel, PanicLevel, FatalLevel}
+	for i, l := range ordered {
+		for j, other := range ordered {
+			if got, want := l.AtLeast(other), i >= j; got != want {
+				t.Errorf("%s.AtLeast(%s) = %v, want %v", l, other, got, want)
+			}
+		}
+	}
+}
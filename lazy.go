@@ -0,0 +1,33 @@
+package logi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+// Lazy is a value that is only computed when an entry logging it is written,
+// so that expensive values are not computed for entries that are dropped.
+// e.g.
+//
+//	logger.Debug("state", "dump", logi.Lazy(func() interface{} { return dump() }))
+//
+// Lazy implements fmt.Stringer, json.Marshaler and slog.LogValuer, which
+// loggers call when encoding the value. The function may be called once for
+// each output that the entry is written to, so it should not have side
+// effects.
+type Lazy func() interface{}
+
+// Lazyf returns a Lazy that formats its arguments using fmt.Sprintf.
+func Lazyf(template string, args ...interface{}) Lazy {
+	return func() interface{} { return fmt.Sprintf(template, args...) }
+}
+
+// String returns the computed value formatted with fmt.Sprint.
+func (l Lazy) String() string { return fmt.Sprint(l()) }
+
+// MarshalJSON returns the computed value marshalled as JSON.
+func (l Lazy) MarshalJSON() ([]byte, error) { return json.Marshal(l()) }
+
+// LogValue returns the computed value as a slog.Value.
+func (l Lazy) LogValue() slog.Value { return slog.AnyValue(l()) }
@@ -0,0 +1,122 @@
+package logi
+
+import (
+	"context"
+
+	"github.com/lohvht/logi/iface"
+)
+
+// The functions below log via the default logger, see Get. They report the
+// caller of the function rather than logi itself, so they may be used in
+// place of Get().Info(...) etc. without having to tune the caller skip.
+
+// Debug logs a message with some additional context in debug level via the
+// default logger.
+func Debug(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Load().skipped.Debug(msg, keysAndValues...)
+}
+
+// Debugf uses fmt.Sprintf to log a templated message in debug level via the
+// default logger.
+func Debugf(template string, args ...interface{}) {
+	defaultLogger.Load().skipped.Debugf(template, args...)
+}
+
+// Info logs a message with some additional context in info level via the
+// default logger.
+func Info(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Load().skipped.Info(msg, keysAndValues...)
+}
+
+// Infof uses fmt.Sprintf to log a templated message in info level via the
+// default logger.
+func Infof(template string, args ...interface{}) {
+	defaultLogger.Load().skipped.Infof(template, args...)
+}
+
+// Warn logs a message with some additional context in warn level via the
+// default logger.
+func Warn(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Load().skipped.Warn(msg, keysAndValues...)
+}
+
+// Warnf uses fmt.Sprintf to log a templated message in warn level via the
+// default logger.
+func Warnf(template string, args ...interface{}) {
+	defaultLogger.Load().skipped.Warnf(template, args...)
+}
+
+// Error logs a message with some additional context in error level via the
+// default logger.
+func Error(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Load().skipped.Error(msg, keysAndValues...)
+}
+
+// Errorf uses fmt.Sprintf to log a templated message in error level via the
+// default logger.
+func Errorf(template string, args ...interface{}) {
+	defaultLogger.Load().skipped.Errorf(template, args...)
+}
+
+// DPanic logs a message with some additional context in dpanic level via the
+// default logger, which panics if the logger is in development mode.
+func DPanic(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Load().skipped.DPanic(msg, keysAndValues...)
+}
+
+// DPanicf uses fmt.Sprintf to log a templated message in dpanic level via the
+// default logger, which panics if the logger is in development mode.
+func DPanicf(template string, args ...interface{}) {
+	defaultLogger.Load().skipped.DPanicf(template, args...)
+}
+
+// Panic logs a message with some additional context in panic level via the
+// default logger and then proceeds to panic.
+func Panic(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Load().skipped.Panic(msg, keysAndValues...)
+}
+
+// Panicf uses fmt.Sprintf to log a templated message in panic level via the
+// default logger and then proceeds to panic.
+func Panicf(template string, args ...interface{}) {
+	defaultLogger.Load().skipped.Panicf(template, args...)
+}
+
+// Fatal logs a message with some additional context in fatal level via the
+// default logger and then exits.
+func Fatal(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Load().skipped.Fatal(msg, keysAndValues...)
+}
+
+// Fatalf uses fmt.Sprintf to log a templated message in fatal level via the
+// default logger and then exits.
+func Fatalf(template string, args ...interface{}) {
+	defaultLogger.Load().skipped.Fatalf(template, args...)
+}
+
+// Log logs a message with some additional context in the given level via the
+// default logger. See iface.Logger.Log.
+func Log(level iface.Level, msg string, keysAndValues ...interface{}) {
+	defaultLogger.Load().skipped.Log(level, msg, keysAndValues...)
+}
+
+// Logf uses fmt.Sprintf to log a templated message in the given level via the
+// default logger. See iface.Logger.Logf.
+func Logf(level iface.Level, template string, args ...interface{}) {
+	defaultLogger.Load().skipped.Logf(level, template, args...)
+}
+
+// With returns a logger derived from the default logger with the additional
+// context. See iface.Logger.With.
+func With(args ...interface{}) iface.Logger { return Get().With(args...) }
+
+// WithContext returns a logger derived from the default logger with the
+// context extracted from ctx. See iface.Logger.WithContext.
+func WithContext(ctx context.Context) iface.Logger { return Get().WithContext(ctx) }
+
+// Named returns a logger derived from the default logger with the given name.
+func Named(loggerName string) iface.Logger { return Get().Named(loggerName) }
+
+// Enabled returns true if the default logger logs entries at the given level.
+// See iface.Logger.Enabled.
+func Enabled(level iface.Level) bool { return Get().Enabled(level) }
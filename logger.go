@@ -1,16 +1,99 @@
 package logi
 
 import (
+	"context"
+	"sync/atomic"
+
+	"go.uber.org/multierr"
+
 	"github.com/lohvht/logi/iface"
 	"github.com/lohvht/logi/zaplogi"
 )
 
-var defaultLogger iface.Logger = zaplogi.NewConsole()
+// loggerHolder holds the default logger. iface.Logger is an interface, so it
+// is wrapped in a struct to be stored in an atomic.Pointer.
+type loggerHolder struct {
+	logger iface.Logger
+	// skipped is logger with an additional caller skip for the package level
+	// logging functions, so that they report their caller instead of logi.
+	skipped iface.Logger
+}
+
+func newLoggerHolder(l iface.Logger) *loggerHolder {
+	if l == nil {
+		// Unlike Nop, a discarding zaplogi.Logger still panics and exits.
+		l = zaplogi.NewDiscard()
+	}
+	return &loggerHolder{logger: l, skipped: l.CallSkip(1)}
+}
 
-// SetDefault replaces the default logger used by logi
-func SetDefault(l iface.Logger) { defaultLogger = l }
+var defaultLogger atomic.Pointer[loggerHolder]
+
+func init() {
+	SetDefault(zaplogi.NewConsole())
+}
+
+// SetDefault replaces the default logger used by logi. It is safe to call
+// concurrently with Get. Setting a nil logger discards all logs, as if
+// zaplogi.NewDiscard was set.
+func SetDefault(l iface.Logger) { defaultLogger.Store(newLoggerHolder(l)) }
 
 // Get returns logi's current default logger
 // By default, the logger uses zap and logs only to Stdout and Stderr, you will
 // need to set SetDefault to change the logger
-func Get() iface.Logger { return defaultLogger }
+func Get() iface.Logger { return defaultLogger.Load().logger }
+
+type replaceOptions struct {
+	syncPrevious  bool
+	closePrevious bool
+}
+
+// ReplaceOption configures ReplaceDefault.
+type ReplaceOption func(*replaceOptions)
+
+// SyncPrevious syncs the previous default logger after it has been replaced.
+func SyncPrevious() ReplaceOption {
+	return func(o *replaceOptions) { o.syncPrevious = true }
+}
+
+// ClosePrevious closes the previous default logger after it has been
+// replaced. The restore function returned by ReplaceDefault will then restore
+// a closed logger, so only use this when the previous logger is not going to
+// be restored.
+func ClosePrevious() ReplaceOption {
+	return func(o *replaceOptions) { o.closePrevious = true }
+}
+
+// ReplaceDefault replaces the default logger used by logi with l, and returns
+// a function that restores the previous default logger. The previous logger
+// is synced or closed according to opts, after it has been replaced; the
+// returned error is from doing so, the default logger is replaced regardless.
+func ReplaceDefault(l iface.Logger, opts ...ReplaceOption) (restore func(), err error) {
+	var o replaceOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	prev := defaultLogger.Swap(newLoggerHolder(l))
+	restore = func() { defaultLogger.Store(prev) }
+	if o.syncPrevious {
+		err = prev.logger.Sync()
+	}
+	if o.closePrevious {
+		err = multierr.Append(err, prev.logger.Close())
+	}
+	return restore, err
+}
+
+// Shutdown flushes and closes the default logger. If ctx is done before the
+// default logger has been closed, Shutdown returns ctx.Err() without waiting
+// any further.
+func Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- Get().Close() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,84 @@
+package logi_test
+
+import (
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/lohvht/logi"
+	"github.com/lohvht/logi/iface"
+	"github.com/lohvht/logi/zaplogi"
+)
+
+func TestSetDefaultConcurrent(t *testing.T) {
+	restore, err := logi.ReplaceDefault(logi.Get())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restore()
+	logger, err := zaplogi.NewWithConfig(zaplogi.LogConfig{
+		LogFileConfigs: []zaplogi.LogFileConfig{
+			{LogRange: [2]zaplogi.Level{zaplogi.InfoLevel, zaplogi.MaxLevel}, Writer: io.Discard},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logi.SetDefault(logger)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logi.Get().Info("concurrent")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestReplaceDefault(t *testing.T) {
+	prev := logi.Get()
+	logger, err := zaplogi.NewWithConfig(zaplogi.LogConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	restore, err := logi.ReplaceDefault(logger, logi.SyncPrevious())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logi.Get() != logger {
+		t.Errorf("Get() = %v, want the replacement logger", logi.Get())
+	}
+	restore()
+	if logi.Get() != prev {
+		t.Errorf("Get() = %v, want the previous logger after restore", logi.Get())
+	}
+}
+
+func TestSetDefaultNil(t *testing.T) {
+	restore, err := logi.ReplaceDefault(logi.Get())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restore()
+	for _, l := range []iface.Logger{nil, (*zaplogi.Logger)(nil)} {
+		logi.SetDefault(l)
+		logi.Info("discarded", "key", "value")
+		logi.With("key", "value").Named("name").Info("discarded")
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Panic did not panic with the default logger set to %#v", l)
+				}
+			}()
+			logi.Panic("discarded")
+		}()
+	}
+}
@@ -0,0 +1,363 @@
+// logitest provides an iface.Logger that records log entries in memory, so
+// that tests may assert on what is logged.
+package logitest
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lohvht/logi"
+	"github.com/lohvht/logi/iface"
+)
+
+// BadKey is the key of values that are not preceded by a string key.
+const BadKey = "!BADKEY"
+
+// Field is a key-value pair logged with an entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Entry is a recorded log entry.
+type Entry struct {
+	Time       time.Time
+	Level      iface.Level
+	LoggerName string
+	Message    string
+	// Fields are the fields of the entry in the order they were added,
+	// starting with those added via With and WithContext.
+	Fields []Field
+	// Caller is the call site that logged the entry.
+	Caller runtime.Frame
+}
+
+// Field returns the value of the last field with the given key.
+func (e Entry) Field(key string) (interface{}, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == key {
+			return e.Fields[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// String returns the entry formatted as e.g. "INFO db logger.go:10 msg key=value".
+func (e Entry) String() string {
+	var b strings.Builder
+	b.WriteString(e.Level.CapitalString())
+	if e.LoggerName != "" {
+		b.WriteString(" " + e.LoggerName)
+	}
+	if e.Caller.File != "" {
+		fmt.Fprintf(&b, " %s:%d", filepath.Base(e.Caller.File), e.Caller.Line)
+	}
+	b.WriteString(" " + e.Message)
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+	}
+	return b.String()
+}
+
+// Entries are recorded log entries, which may be filtered further.
+type Entries []Entry
+
+// FilterLevel returns the entries logged at lvl.
+func (es Entries) FilterLevel(lvl iface.Level) Entries {
+	return es.Filter(func(e Entry) bool { return e.Level == lvl })
+}
+
+// FilterMessage returns the entries with the message msg.
+func (es Entries) FilterMessage(msg string) Entries {
+	return es.Filter(func(e Entry) bool { return e.Message == msg })
+}
+
+// FilterMessageContains returns the entries with messages containing substr.
+func (es Entries) FilterMessageContains(substr string) Entries {
+	return es.Filter(func(e Entry) bool { return strings.Contains(e.Message, substr) })
+}
+
+// FilterLoggerName returns the entries logged by the logger named name.
+func (es Entries) FilterLoggerName(name string) Entries {
+	return es.Filter(func(e Entry) bool { return e.LoggerName == name })
+}
+
+// FilterField returns the entries with a field of the given key and value,
+// compared using reflect.DeepEqual.
+func (es Entries) FilterField(key string, value interface{}) Entries {
+	return es.Filter(func(e Entry) bool {
+		v, ok := e.Field(key)
+		return ok && reflect.DeepEqual(v, value)
+	})
+}
+
+// Filter returns the entries for which keep returns true.
+func (es Entries) Filter(keep func(Entry) bool) Entries {
+	var filtered Entries
+	for _, e := range es {
+		if keep(e) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// Len returns the number of entries.
+func (es Entries) Len() int { return len(es) }
+
+// recorder records the entries of a Logger and all loggers derived from it.
+type recorder struct {
+	mu      sync.Mutex
+	entries Entries
+	tb      testing.TB
+	// done is set once the test of tb has completed, after which tb must not
+	// be logged to.
+	done bool
+}
+
+func (r *recorder) record(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, e)
+	if r.tb != nil && !r.done {
+		r.tb.Log(e.String())
+	}
+}
+
+// Logger is an iface.Logger that records the entries logged in memory. All
+// levels are recorded. Panic and Panicf panic after recording the entry like
+// other loggers, while Fatal and Fatalf panic as well instead of exiting, so
+// that tests may recover from them.
+type Logger struct {
+	rec      *recorder
+	name     string
+	fields   []Field
+	callSkip int
+}
+
+// New returns a Logger that only records entries.
+func New() *Logger {
+	return &Logger{rec: &recorder{}}
+}
+
+// NewT returns a Logger that records entries and logs them via tb.Log until
+// the test completes.
+func NewT(tb testing.TB) *Logger {
+	rec := &recorder{tb: tb}
+	tb.Cleanup(func() {
+		rec.mu.Lock()
+		rec.done = true
+		rec.mu.Unlock()
+	})
+	return &Logger{rec: rec}
+}
+
+// SetDefault sets a new Logger from NewT as the default logger of the logi
+// package, and restores the previous default logger once the test completes.
+func SetDefault(tb testing.TB) *Logger {
+	l := NewT(tb)
+	restore, err := logi.ReplaceDefault(l)
+	if err != nil {
+		tb.Fatalf("logitest: replacing the default logger: %v", err)
+	}
+	tb.Cleanup(restore)
+	return l
+}
+
+// Entries returns the entries recorded so far by the Logger, and all loggers
+// derived from it or that it was derived from.
+func (l *Logger) Entries() Entries {
+	l.rec.mu.Lock()
+	defer l.rec.mu.Unlock()
+	return append(Entries(nil), l.rec.entries...)
+}
+
+// Reset discards all recorded entries.
+func (l *Logger) Reset() {
+	l.rec.mu.Lock()
+	defer l.rec.mu.Unlock()
+	l.rec.entries = nil
+}
+
+// AssertLogged reports an error to tb unless an entry was recorded with the
+// level lvl, the message msg and the fields of keysAndValues, among others.
+func (l *Logger) AssertLogged(tb testing.TB, lvl iface.Level, msg string, keysAndValues ...interface{}) bool {
+	tb.Helper()
+	entries := l.Entries()
+	matched := entries.FilterLevel(lvl).FilterMessage(msg)
+	for _, f := range toFields(keysAndValues) {
+		matched = matched.FilterField(f.Key, f.Value)
+	}
+	if len(matched) > 0 {
+		return true
+	}
+	var want strings.Builder
+	fmt.Fprintf(&want, "%s %s", lvl.CapitalString(), msg)
+	for _, f := range toFields(keysAndValues) {
+		fmt.Fprintf(&want, " %s=%v", f.Key, f.Value)
+	}
+	var got strings.Builder
+	for _, e := range entries {
+		got.WriteString("\n\t" + e.String())
+	}
+	tb.Errorf("logitest: no entry logged matching %q, got %d entries:%s", want.String(), len(entries), got.String())
+	return false
+}
+
+func (l *Logger) log(lvl iface.Level, msg string, keysAndValues []interface{}) {
+	var pcs [1]uintptr
+	// skip runtime.Callers, log and the exported logging method.
+	runtime.Callers(3+l.callSkip, pcs[:])
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	fields := append(l.fields[:len(l.fields):len(l.fields)], toFields(keysAndValues)...)
+	l.rec.record(Entry{
+		Time:       time.Now(),
+		Level:      lvl,
+		LoggerName: l.name,
+		Message:    msg,
+		Fields:     fields,
+		Caller:     frame,
+	})
+}
+
+// toFields converts keysAndValues to fields. Values not preceded by a string
+// key are given the key BadKey.
+func toFields(keysAndValues []interface{}) []Field {
+	var fields []Field
+	for i := 0; i < len(keysAndValues); {
+		key, ok := keysAndValues[i].(string)
+		if !ok || i == len(keysAndValues)-1 {
+			fields = append(fields, Field{Key: BadKey, Value: keysAndValues[i]})
+			i++
+			continue
+		}
+		fields = append(fields, Field{Key: key, Value: keysAndValues[i+1]})
+		i += 2
+	}
+	return fields
+}
+
+func (l *Logger) Debug(msg string, keysAndValues ...interface{}) {
+	l.log(iface.DebugLevel, msg, keysAndValues)
+}
+
+func (l *Logger) Debugf(template string, args ...interface{}) {
+	l.log(iface.DebugLevel, fmt.Sprintf(template, args...), nil)
+}
+
+func (l *Logger) Info(msg string, keysAndValues ...interface{}) {
+	l.log(iface.InfoLevel, msg, keysAndValues)
+}
+
+func (l *Logger) Infof(template string, args ...interface{}) {
+	l.log(iface.InfoLevel, fmt.Sprintf(template, args...), nil)
+}
+
+func (l *Logger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log(iface.WarnLevel, msg, keysAndValues)
+}
+
+func (l *Logger) Warnf(template string, args ...interface{}) {
+	l.log(iface.WarnLevel, fmt.Sprintf(template, args...), nil)
+}
+
+func (l *Logger) Error(msg string, keysAndValues ...interface{}) {
+	l.log(iface.ErrorLevel, msg, keysAndValues)
+}
+
+func (l *Logger) Errorf(template string, args ...interface{}) {
+	l.log(iface.ErrorLevel, fmt.Sprintf(template, args...), nil)
+}
+
+// DPanic records the entry without panicking.
+func (l *Logger) DPanic(msg string, keysAndValues ...interface{}) {
+	l.log(iface.DPanicLevel, msg, keysAndValues)
+}
+
+func (l *Logger) DPanicf(template string, args ...interface{}) {
+	l.log(iface.DPanicLevel, fmt.Sprintf(template, args...), nil)
+}
+
+func (l *Logger) Panic(msg string, keysAndValues ...interface{}) {
+	l.log(iface.PanicLevel, msg, keysAndValues)
+	panic(msg)
+}
+
+func (l *Logger) Panicf(template string, args ...interface{}) {
+	msg := fmt.Sprintf(template, args...)
+	l.log(iface.PanicLevel, msg, nil)
+	panic(msg)
+}
+
+func (l *Logger) Fatal(msg string, keysAndValues ...interface{}) {
+	l.log(iface.FatalLevel, msg, keysAndValues)
+	panic(msg)
+}
+
+func (l *Logger) Fatalf(template string, args ...interface{}) {
+	msg := fmt.Sprintf(template, args...)
+	l.log(iface.FatalLevel, msg, nil)
+	panic(msg)
+}
+
+// Log records the entry, and panics for the panic and fatal levels.
+func (l *Logger) Log(level iface.Level, msg string, keysAndValues ...interface{}) {
+	l.log(level, msg, keysAndValues)
+	if level == iface.PanicLevel || level == iface.FatalLevel {
+		panic(msg)
+	}
+}
+
+func (l *Logger) Logf(level iface.Level, template string, args ...interface{}) {
+	msg := fmt.Sprintf(template, args...)
+	l.log(level, msg, nil)
+	if level == iface.PanicLevel || level == iface.FatalLevel {
+		panic(msg)
+	}
+}
+
+// Enabled returns true for all levels, as all entries are recorded.
+func (l *Logger) Enabled(level iface.Level) bool { return true }
+
+func (l *Logger) With(args ...interface{}) iface.Logger {
+	newLogger := *l
+	newLogger.fields = append(l.fields[:len(l.fields):len(l.fields)], toFields(args)...)
+	return &newLogger
+}
+
+func (l *Logger) WithContext(ctx context.Context) iface.Logger {
+	kvs := iface.ExtractContext(ctx)
+	if len(kvs) == 0 {
+		return l
+	}
+	return l.With(kvs...)
+}
+
+func (l *Logger) Named(loggerName string) iface.Logger {
+	newLogger := *l
+	if l.name == "" {
+		newLogger.name = loggerName
+	} else if loggerName != "" {
+		newLogger.name = l.name + "." + loggerName
+	}
+	return &newLogger
+}
+
+func (l *Logger) CallSkip(skips int) iface.Logger {
+	newLogger := *l
+	newLogger.callSkip += skips
+	return &newLogger
+}
+
+// Sync does nothing, as entries are recorded synchronously.
+func (l *Logger) Sync() error { return nil }
+
+// Close does nothing, as there is nothing to close.
+func (l *Logger) Close() error { return nil }
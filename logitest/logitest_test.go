@@ -0,0 +1,84 @@
+package logitest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lohvht/logi"
+	"github.com/lohvht/logi/logitest"
+	"github.com/lohvht/logi/zaplogi"
+)
+
+func TestSetDefault(t *testing.T) {
+	prev := logi.Get()
+	t.Run("swapped", func(t *testing.T) {
+		l := logitest.SetDefault(t)
+		logi.Named("db").Warn("slow query", "ms", 250)
+		l.AssertLogged(t, zaplogi.WarnLevel, "slow query", "ms", 250)
+		e := l.Entries()[0]
+		if e.LoggerName != "db" || !strings.HasSuffix(e.Caller.File, "logitest_test.go") {
+			t.Errorf("got entry %v, want logger name db and caller in logitest_test.go", e)
+		}
+	})
+	if logi.Get() != prev {
+		t.Error("default logger was not restored")
+	}
+}
+
+// recordingTB records the errors reported via Errorf.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestLoggerAssertLogged(t *testing.T) {
+	l := logitest.New()
+	l.With("user", "alice").Info("logged in", "attempts", 2)
+	tb := &recordingTB{TB: t}
+	if !l.AssertLogged(tb, zaplogi.InfoLevel, "logged in", "user", "alice") {
+		t.Errorf("AssertLogged: got false for a matching entry, errors: %v", tb.errors)
+	}
+	if l.AssertLogged(tb, zaplogi.InfoLevel, "logged in", "attempts", 3) {
+		t.Error("AssertLogged: got true for a field that does not match")
+	}
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "logged in user=alice attempts=2") {
+		t.Errorf("AssertLogged: got errors %q, want one listing the logged entry", tb.errors)
+	}
+}
+
+func TestLoggerFatal(t *testing.T) {
+	l := logitest.New()
+	func() {
+		defer func() {
+			if r := recover(); r != "shutting down" {
+				t.Errorf("recovered %v, want the message", r)
+			}
+		}()
+		l.Fatalf("shutting %s", "down")
+	}()
+	if l.Entries().FilterLevel(zaplogi.FatalLevel).Len() != 1 {
+		t.Errorf("got entries %v, want a fatal entry", l.Entries())
+	}
+}
+
+func ExampleNew() {
+	l := logitest.New()
+	db := l.Named("db").With("conn", 1)
+	db.Info("connected")
+	db.Warnf("%d slow queries", 2)
+	l.Error("request failed", "status", 500, "path", "/")
+
+	for _, e := range l.Entries().FilterField("conn", 1) {
+		fmt.Println(e.Level, e.LoggerName, e.Message)
+	}
+	fmt.Println(l.Entries().FilterLevel(zaplogi.ErrorLevel)[0].Field("status"))
+	// Output:
+	// info db connected
+	// warn db 2 slow queries
+	// 500 true
+}
@@ -0,0 +1,66 @@
+package logi
+
+import (
+	"context"
+
+	"github.com/lohvht/logi/iface"
+)
+
+// NopLogger is an iface.Logger that discards everything, without allocating.
+// Unlike other loggers, Panic, Panicf, Fatal and Fatalf do nothing as well.
+// Its zero value is ready to use.
+//
+// Note that when called via iface.Logger, passing keysAndValues or args may
+// still allocate at the call site, as the compiler cannot tell that they do
+// not escape. Hold a NopLogger instead to avoid that.
+type NopLogger struct{}
+
+// Nop returns a NopLogger, for libraries that are silent by default and for
+// benchmarks.
+func Nop() NopLogger { return NopLogger{} }
+
+func (NopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+
+func (NopLogger) Debugf(template string, args ...interface{}) {}
+
+func (NopLogger) Info(msg string, keysAndValues ...interface{}) {}
+
+func (NopLogger) Infof(template string, args ...interface{}) {}
+
+func (NopLogger) Warn(msg string, keysAndValues ...interface{}) {}
+
+func (NopLogger) Warnf(template string, args ...interface{}) {}
+
+func (NopLogger) Error(msg string, keysAndValues ...interface{}) {}
+
+func (NopLogger) Errorf(template string, args ...interface{}) {}
+
+func (NopLogger) DPanic(msg string, keysAndValues ...interface{}) {}
+
+func (NopLogger) DPanicf(template string, args ...interface{}) {}
+
+func (NopLogger) Panic(msg string, keysAndValues ...interface{}) {}
+
+func (NopLogger) Panicf(template string, args ...interface{}) {}
+
+func (NopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+func (NopLogger) Fatalf(template string, args ...interface{}) {}
+
+func (NopLogger) Log(level iface.Level, msg string, keysAndValues ...interface{}) {}
+
+func (NopLogger) Logf(level iface.Level, template string, args ...interface{}) {}
+
+func (NopLogger) Enabled(level iface.Level) bool { return false }
+
+func (l NopLogger) With(args ...interface{}) iface.Logger { return l }
+
+func (l NopLogger) WithContext(ctx context.Context) iface.Logger { return l }
+
+func (l NopLogger) Named(loggerName string) iface.Logger { return l }
+
+func (l NopLogger) CallSkip(skips int) iface.Logger { return l }
+
+func (NopLogger) Sync() error { return nil }
+
+func (NopLogger) Close() error { return nil }
@@ -0,0 +1,56 @@
+package logi_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lohvht/logi"
+	"github.com/lohvht/logi/iface"
+)
+
+var errBench = errors.New("boom")
+
+// nopLogger is a package variable, so that calls to it are not devirtualised.
+var nopLogger iface.Logger = logi.Nop()
+
+func TestNopAllocs(t *testing.T) {
+	l := logi.Nop()
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(100, func() {
+		l.With("key", "value").Named("db").WithContext(ctx).CallSkip(1).Info("msg", "n", 42, "err", errBench)
+		l.Errorf("%s failed", "request")
+		nopLogger.Named("db").WithContext(ctx).CallSkip(1).Info("msg")
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocs, want 0", allocs)
+	}
+}
+
+func BenchmarkNop(b *testing.B) {
+	l := logi.Nop()
+	b.Run("Info", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Info("msg", "key", "value", "err", errBench)
+		}
+	})
+	b.Run("Infof", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Infof("%s failed", "request")
+		}
+	})
+	b.Run("With", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.With("key", "value").Named("db").Info("msg")
+		}
+	})
+	b.Run("Iface", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			nopLogger.Named("db").Info("msg")
+		}
+	})
+}
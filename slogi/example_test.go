@@ -0,0 +1,99 @@
+package slogi_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lohvht/logi/slogi"
+	"github.com/lohvht/logi/zaplogi"
+)
+
+func ExampleNew() {
+	h := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		AddSource: true,
+		Level:     slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			switch a.Key {
+			case slog.TimeKey:
+				return slog.Attr{}
+			case slog.SourceKey:
+				a.Value = slog.StringValue(filepath.Base(a.Value.Any().(*slog.Source).File))
+			}
+			return a
+		},
+	})
+	logger := slogi.New(h)
+	logger.Named("db").With("table", "users").Debug("querying", "rows", 3)
+	logger.Warnf("retrying in %ds", 5)
+	// Output:
+	// level=DEBUG source=example_test.go msg=querying table=users logger=db rows=3
+	// level=WARN source=example_test.go msg="retrying in 5s"
+}
+
+func ExampleNewHandler() {
+	var buf bytes.Buffer
+	zl, err := zaplogi.NewWithConfig(zaplogi.LogConfig{
+		RootCallerSkip: 1,
+		LogFileConfigs: []zaplogi.LogFileConfig{
+			{LoggerName: "db", LogRange: [2]zaplogi.Level{zaplogi.DebugLevel, zaplogi.MaxLevel}, Writer: &buf},
+		},
+	})
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	logger := slog.New(slogi.NewHandler(zl.Named("db"), nil))
+	logger.WithGroup("query").Debug("done", "rows", 3, slog.Group("timing", "ms", 12))
+	logger.Error("failed", "err", "timeout")
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		cols := strings.Split(line, "\t")
+		// drop the timestamp and the caller's line number
+		cols[3] = strings.Split(cols[3], ":")[0]
+		fmt.Println(strings.Join(cols[1:], " "))
+	}
+	// Output:
+	// DEBUG db slogi/example_test.go done {"query.rows": 3, "query.timing.ms": 12}
+	// ERROR db slogi/example_test.go failed {"err": "timeout"}
+}
+
+func ExampleNewHandler_customLevels() {
+	var buf bytes.Buffer
+	zl, err := zaplogi.NewWithConfig(zaplogi.LogConfig{
+		EncoderConfig: zaplogi.EncoderConfig{TimeKey: "-", CallerFormat: zaplogi.DisabledCallerFormat},
+		LogFileConfigs: []zaplogi.LogFileConfig{
+			{LogRange: [2]zaplogi.Level{zaplogi.TraceLevel, zaplogi.MaxLevel}, Writer: &buf},
+		},
+	})
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	logger := slog.New(slogi.NewHandler(zl, nil))
+	logger.Log(context.Background(), slog.LevelDebug-4, "tracing")
+	logger.Log(context.Background(), slog.LevelInfo+2, "noticed")
+	fmt.Print(buf.String())
+
+	// Levels round trip from slog into an iface.Logger and back.
+	h := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug - 4,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger = slog.New(slogi.NewHandler(slogi.New(h), nil))
+	logger.Log(context.Background(), slog.LevelDebug-4, "tracing")
+	logger.Log(context.Background(), slog.LevelInfo+2, "noticed")
+	// Output:
+	// TRACE	tracing
+	// NOTICE	noticed
+	// level=DEBUG-4 msg=tracing
+	// level=INFO+2 msg=noticed
+}
@@ -0,0 +1,175 @@
+package slogi
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync/atomic"
+
+	"github.com/lohvht/logi/iface"
+)
+
+// handlerCallSkip is the number of frames between the caller of a slog.Logger
+// method and the iface.Logger method called by Handler.Handle, i.e. the
+// slog.Logger method, its internal log method and Handler.Handle.
+const handlerCallSkip = 3
+
+// HandlerOptions are options for a Handler.
+type HandlerOptions struct {
+	// Level reports the minimum level to forward. If nil, the handler forwards
+	// records that the iface.Logger is enabled for at the level they are
+	// forwarded at, see iface.Logger.Enabled.
+	Level slog.Leveler
+}
+
+// Handler is a slog.Handler that forwards records into an iface.Logger, such
+// as a zaplogi.Logger. This allows libraries logging via slog to end up in the
+// same outputs as the rest of the application.
+//
+// Record levels are mapped to the closest iface.Logger level, standard or
+// custom, that a Logger logs at a slog level at most as severe, so that levels
+// round trip between the two. For example, slog.LevelInfo+2 is forwarded at
+// iface.NoticeLevel and slog.LevelDebug-4 at iface.TraceLevel. Records below
+// all such levels are forwarded at the least severe of them. As forwarded
+// records never panic or exit, records above slog.LevelError are logged as
+// errors unless a custom level is closer. Attributes in groups are flattened
+// into keys joined by ".".
+//
+// The caller reported is correct when the handler is called directly by a
+// slog.Logger; handlers wrapping Handler will shift the reported caller.
+type Handler struct {
+	logger iface.Logger
+	level  slog.Leveler
+	// groupPrefix is prepended to the keys of all attributes.
+	groupPrefix string
+}
+
+// NewHandler returns a Handler that forwards records into l.
+func NewHandler(l iface.Logger, opts *HandlerOptions) *Handler {
+	h := &Handler{logger: l.CallSkip(handlerCallSkip)}
+	if opts != nil && opts.Level != nil {
+		h.level = opts.Level
+	}
+	return h
+}
+
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	if h.level != nil {
+		return level >= h.level.Level()
+	}
+	return h.logger.Enabled(ifaceLevel(level))
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	kvs := make([]interface{}, 0, 2*r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		kvs = appendAttr(kvs, h.groupPrefix, a)
+		return true
+	})
+	logger := h.logger
+	if ctx != nil {
+		logger = logger.WithContext(ctx)
+	}
+	logger.Log(ifaceLevel(r.Level), r.Message, kvs...)
+	return nil
+}
+
+// ifaceLevel returns the iface.Logger level that records at level are
+// forwarded at.
+func ifaceLevel(level slog.Level) iface.Level {
+	switch level {
+	case slog.LevelDebug:
+		return iface.DebugLevel
+	case slog.LevelInfo:
+		return iface.InfoLevel
+	case slog.LevelWarn:
+		return iface.WarnLevel
+	case slog.LevelError:
+		return iface.ErrorLevel
+	}
+	m := forwardedLevels()
+	forwarded := m.levels[0]
+	for i, l := range m.levels[1:] {
+		if m.slogLevels[i+1] > level {
+			break
+		}
+		forwarded = l
+	}
+	return forwarded
+}
+
+// levelMapping holds the levels that records are forwarded at for a set of
+// custom levels.
+type levelMapping struct {
+	// custom is the slice returned by iface.CustomLevels that levels was
+	// built from.
+	custom []iface.Level
+	// levels are the custom levels and the standard levels up to
+	// iface.ErrorLevel, ordered by the slog levels they are logged at. The
+	// standard levels come last among levels of the same slog level, so that
+	// they are preferred.
+	levels []iface.Level
+	// slogLevels are the slog levels that levels are logged at.
+	slogLevels []slog.Level
+}
+
+// levelMappings caches the levelMapping of the current custom levels.
+var levelMappings atomic.Pointer[levelMapping]
+
+// forwardedLevels returns the mapping of the current custom levels, which is
+// only worked out again once a custom level has been registered.
+func forwardedLevels() *levelMapping {
+	custom := iface.CustomLevels()
+	if m := levelMappings.Load(); m != nil && len(m.custom) == len(custom) && &m.custom[0] == &custom[0] {
+		return m
+	}
+	levels := append(append([]iface.Level(nil), custom...), iface.DebugLevel, iface.InfoLevel, iface.WarnLevel, iface.ErrorLevel)
+	sort.SliceStable(levels, func(i, j int) bool { return slogLevel(levels[i]) < slogLevel(levels[j]) })
+	m := &levelMapping{custom: custom, levels: levels, slogLevels: make([]slog.Level, len(levels))}
+	for i, l := range levels {
+		m.slogLevels[i] = slogLevel(l)
+	}
+	levelMappings.Store(m)
+	return m
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	kvs := make([]interface{}, 0, 2*len(attrs))
+	for _, a := range attrs {
+		kvs = appendAttr(kvs, h.groupPrefix, a)
+	}
+	newHandler := *h
+	newHandler.logger = h.logger.With(kvs...)
+	return &newHandler
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	newHandler := *h
+	newHandler.groupPrefix = h.groupPrefix + name + "."
+	return &newHandler
+}
+
+// appendAttr appends a as key-value pairs to kvs, flattening groups.
+func appendAttr(kvs []interface{}, prefix string, a slog.Attr) []interface{} {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return kvs
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			kvs = appendAttr(kvs, groupPrefix, ga)
+		}
+		return kvs
+	}
+	return append(kvs, prefix+a.Key, a.Value.Any())
+}
@@ -0,0 +1,249 @@
+// slogi provides a log/slog backed implementation of iface.Logger, as well as
+// a slog.Handler that forwards records into any iface.Logger.
+package slogi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/lohvht/logi/iface"
+)
+
+// slog levels for the iface.Logger levels that slog does not have.
+const (
+	LevelDPanic = slog.Level(10)
+	LevelPanic  = slog.Level(12)
+	LevelFatal  = slog.Level(16)
+)
+
+// NameKey is the attribute key that the logger's name is logged under.
+const NameKey = "logger"
+
+// Logger implements iface.Logger on top of a slog.Handler.
+type Logger struct {
+	handler  slog.Handler
+	ctx      context.Context
+	name     string
+	callSkip int
+}
+
+// New returns a Logger that logs to h.
+func New(h slog.Handler) *Logger {
+	return &Logger{handler: h, ctx: context.Background()}
+}
+
+// Handler returns the slog.Handler that the logger logs to.
+func (l *Logger) Handler() slog.Handler { return l.handler }
+
+// log creates a record and passes it to the handler if the level is enabled.
+// It must be called directly by the exported logging methods for the caller
+// to be reported correctly.
+func (l *Logger) log(level slog.Level, msg string, keysAndValues []interface{}) {
+	if !l.handler.Enabled(l.ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	// skip runtime.Callers, log and the exported logging method.
+	runtime.Callers(3+l.callSkip, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	if l.name != "" {
+		r.AddAttrs(slog.String(NameKey, l.name))
+	}
+	r.Add(keysAndValues...)
+	_ = l.handler.Handle(l.ctx, r)
+}
+
+func (l *Logger) Debug(msg string, keysAndValues ...interface{}) {
+	l.log(slog.LevelDebug, msg, keysAndValues)
+}
+
+func (l *Logger) Debugf(template string, args ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprintf(template, args...), nil)
+}
+
+func (l *Logger) Info(msg string, keysAndValues ...interface{}) {
+	l.log(slog.LevelInfo, msg, keysAndValues)
+}
+
+func (l *Logger) Infof(template string, args ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprintf(template, args...), nil)
+}
+
+func (l *Logger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log(slog.LevelWarn, msg, keysAndValues)
+}
+
+func (l *Logger) Warnf(template string, args ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprintf(template, args...), nil)
+}
+
+func (l *Logger) Error(msg string, keysAndValues ...interface{}) {
+	l.log(slog.LevelError, msg, keysAndValues)
+}
+
+func (l *Logger) Errorf(template string, args ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprintf(template, args...), nil)
+}
+
+// DPanic logs at LevelDPanic. It never panics, as the logger has no
+// development mode.
+func (l *Logger) DPanic(msg string, keysAndValues ...interface{}) {
+	l.log(LevelDPanic, msg, keysAndValues)
+}
+
+func (l *Logger) DPanicf(template string, args ...interface{}) {
+	l.log(LevelDPanic, fmt.Sprintf(template, args...), nil)
+}
+
+func (l *Logger) Panic(msg string, keysAndValues ...interface{}) {
+	l.log(LevelPanic, msg, keysAndValues)
+	panic(msg)
+}
+
+func (l *Logger) Panicf(template string, args ...interface{}) {
+	msg := fmt.Sprintf(template, args...)
+	l.log(LevelPanic, msg, nil)
+	panic(msg)
+}
+
+func (l *Logger) Fatal(msg string, keysAndValues ...interface{}) {
+	l.log(LevelFatal, msg, keysAndValues)
+	os.Exit(1)
+}
+
+func (l *Logger) Fatalf(template string, args ...interface{}) {
+	l.log(LevelFatal, fmt.Sprintf(template, args...), nil)
+	os.Exit(1)
+}
+
+func (l *Logger) Log(level iface.Level, msg string, keysAndValues ...interface{}) {
+	l.log(slogLevel(level), msg, keysAndValues)
+	switch level {
+	case iface.PanicLevel:
+		panic(msg)
+	case iface.FatalLevel:
+		os.Exit(1)
+	}
+}
+
+func (l *Logger) Logf(level iface.Level, template string, args ...interface{}) {
+	msg := fmt.Sprintf(template, args...)
+	l.log(slogLevel(level), msg, nil)
+	switch level {
+	case iface.PanicLevel:
+		panic(msg)
+	case iface.FatalLevel:
+		os.Exit(1)
+	}
+}
+
+// Enabled returns true if the handler handles records at the slog level that
+// level is logged at.
+func (l *Logger) Enabled(level iface.Level) bool {
+	return l.handler.Enabled(l.ctx, slogLevel(level))
+}
+
+func (l *Logger) With(args ...interface{}) iface.Logger {
+	if len(args) == 0 {
+		return l
+	}
+	newLogger := *l
+	newLogger.handler = l.handler.WithAttrs(argsToAttrs(args))
+	return &newLogger
+}
+
+// WithContext returns a logger with the key-value pairs extracted from ctx.
+// ctx is also passed on to the handler when handling records.
+func (l *Logger) WithContext(ctx context.Context) iface.Logger {
+	if ctx == nil {
+		return l
+	}
+	newLogger := *l
+	newLogger.ctx = ctx
+	if kvs := iface.ExtractContext(ctx); len(kvs) > 0 {
+		newLogger.handler = l.handler.WithAttrs(argsToAttrs(kvs))
+	}
+	return &newLogger
+}
+
+// Named returns a new logger with the given name. Like zap, names are joined
+// by "." when Named is called on a logger that already has a name.
+func (l *Logger) Named(loggerName string) iface.Logger {
+	if loggerName == "" {
+		return l
+	}
+	newLogger := *l
+	if l.name == "" {
+		newLogger.name = loggerName
+	} else {
+		newLogger.name = l.name + "." + loggerName
+	}
+	return &newLogger
+}
+
+func (l *Logger) CallSkip(skips int) iface.Logger {
+	newLogger := *l
+	newLogger.callSkip += skips
+	return &newLogger
+}
+
+// Sync calls the Sync method of the handler, if it has one.
+func (l *Logger) Sync() error {
+	if s, ok := l.handler.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close syncs the handler, then calls its Close method, if it has one.
+func (l *Logger) Close() error {
+	err := l.Sync()
+	if c, ok := l.handler.(io.Closer); ok {
+		err = multierr.Append(err, c.Close())
+	}
+	return err
+}
+
+// argsToAttrs converts key-value pairs to attributes in the same way as
+// slog.Record.Add does.
+func argsToAttrs(args []interface{}) []slog.Attr {
+	r := slog.NewRecord(time.Time{}, 0, "", 0)
+	r.Add(args...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
+
+// slogLevel returns the slog level that level is logged at. Custom levels
+// are scaled from their severities, e.g. iface.TraceLevel is logged at
+// slog.LevelDebug-4 and iface.NoticeLevel at slog.LevelInfo+2.
+func slogLevel(level iface.Level) slog.Level {
+	switch level {
+	case iface.DebugLevel:
+		return slog.LevelDebug
+	case iface.InfoLevel:
+		return slog.LevelInfo
+	case iface.WarnLevel:
+		return slog.LevelWarn
+	case iface.ErrorLevel:
+		return slog.LevelError
+	case iface.DPanicLevel:
+		return LevelDPanic
+	case iface.PanicLevel:
+		return LevelPanic
+	case iface.FatalLevel:
+		return LevelFatal
+	default:
+		return slog.Level(level.Severity() * 4 / 10)
+	}
+}
@@ -0,0 +1,282 @@
+package zaplogi
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// defaultAsyncQueueSize is the queue size used when AsyncConfig.QueueSize
+	// is not set.
+	defaultAsyncQueueSize = 1024
+	// defaultAsyncFlushInterval is the flush interval used when
+	// AsyncConfig.FlushInterval is not set.
+	defaultAsyncFlushInterval = time.Second
+)
+
+// errAsyncWriterClosed is returned when writing to a closed AsyncWriter.
+var errAsyncWriterClosed = errors.New("async writer is closed")
+
+// AsyncConfig configures writing to a log file asynchronously.
+type AsyncConfig struct {
+	// QueueSize is the maximum number of entries queued to be written.
+	// Defaults to 1024.
+	QueueSize int `json:"queue_size,omitempty" toml:"queue_size,omitempty" yaml:"queue-size,omitempty"`
+	// Overflow determines what happens to entries written when the queue is
+	// full, either "block", "drop_newest" or "drop_oldest". Defaults to
+	// "block".
+	Overflow OverflowPolicy `json:"overflow,omitempty" toml:"overflow,omitempty" yaml:"overflow,omitempty"`
+	// FlushInterval is the maximum time that written entries are buffered
+	// for before being flushed to the log file, e.g. "500ms". Defaults to 1
+	// second.
+	FlushInterval Duration `json:"flush_interval,omitempty" toml:"flush_interval,omitempty" yaml:"flush-interval,omitempty"`
+	// FlushSize is the number of buffered bytes at which the buffer is
+	// flushed to the log file before FlushInterval is up. If 0, entries are
+	// flushed as soon as they are dequeued.
+	FlushSize int `json:"flush_size,omitempty" toml:"flush_size,omitempty" yaml:"flush-size,omitempty"`
+}
+
+// AsyncStats are the metrics of an AsyncWriter.
+type AsyncStats struct {
+	// QueueDepth is the number of entries currently queued.
+	QueueDepth int
+	// QueueSize is the maximum number of entries queued.
+	QueueSize int
+	// Dropped is the total number of entries dropped as the queue was full.
+	Dropped uint64
+}
+
+// AsyncWriter is a zapcore.WriteSyncer that queues writes in a bounded queue,
+// which is drained by a background goroutine into the wrapped WriteSyncer.
+// Sync waits for all queued writes to be flushed, and Close stops the
+// background goroutine after flushing. Close does not close the wrapped
+// WriteSyncer.
+type AsyncWriter struct {
+	ws            zapcore.WriteSyncer
+	overflow      OverflowPolicy
+	queueSize     int
+	flushInterval time.Duration
+	flushSize     int
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	queue   [][]byte
+	dropped uint64
+	closed  bool
+
+	wake      chan struct{}
+	syncReqs  chan chan error
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	// closeErr is the error from the final flush, set before done is closed.
+	closeErr error
+}
+
+// NewAsyncWriter returns an AsyncWriter writing to ws, and starts its
+// background goroutine.
+func NewAsyncWriter(ws zapcore.WriteSyncer, c AsyncConfig) *AsyncWriter {
+	w := &AsyncWriter{
+		ws:            ws,
+		overflow:      c.Overflow,
+		queueSize:     c.QueueSize,
+		flushInterval: time.Duration(c.FlushInterval),
+		flushSize:     c.FlushSize,
+		wake:          make(chan struct{}, 1),
+		syncReqs:      make(chan chan error),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	if w.queueSize <= 0 {
+		w.queueSize = defaultAsyncQueueSize
+	}
+	if w.flushInterval <= 0 {
+		w.flushInterval = defaultAsyncFlushInterval
+	}
+	w.notFull = sync.NewCond(&w.mu)
+	go w.run()
+	return w
+}
+
+// Write queues a copy of p to be written, applying the overflow policy if the
+// queue is full.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	b := append([]byte(nil), p...)
+	w.mu.Lock()
+	for !w.closed && len(w.queue) >= w.queueSize && w.overflow == BlockOverflow {
+		w.notFull.Wait()
+	}
+	if w.closed {
+		w.mu.Unlock()
+		return 0, errAsyncWriterClosed
+	}
+	if len(w.queue) >= w.queueSize {
+		w.dropped++
+		if w.overflow == DropNewestOverflow {
+			w.mu.Unlock()
+			return len(p), nil
+		}
+		w.queue[0] = nil
+		w.queue = w.queue[1:]
+	}
+	w.queue = append(w.queue, b)
+	w.mu.Unlock()
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// Sync waits for all queued writes to be flushed, then syncs the wrapped
+// WriteSyncer. Errors from writing to the wrapped WriteSyncer since the last
+// Sync are returned as well.
+func (w *AsyncWriter) Sync() error {
+	reply := make(chan error, 1)
+	select {
+	case w.syncReqs <- reply:
+		return <-reply
+	case <-w.done:
+		return nil
+	}
+}
+
+// Close flushes all queued writes, syncs the wrapped WriteSyncer and stops the
+// background goroutine. Errors from writing to the wrapped WriteSyncer since
+// the last Sync are returned as well. Subsequent writes return an error.
+func (w *AsyncWriter) Close() error {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.notFull.Broadcast()
+		w.mu.Unlock()
+		close(w.stop)
+	})
+	<-w.done
+	return w.closeErr
+}
+
+// Stats returns the current metrics of the writer.
+func (w *AsyncWriter) Stats() AsyncStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return AsyncStats{QueueDepth: len(w.queue), QueueSize: w.queueSize, Dropped: w.dropped}
+}
+
+// run drains the queue until the writer is closed.
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+	var buf bytes.Buffer
+	var errs error
+	for {
+		select {
+		case <-w.wake:
+			errs = multierr.Append(errs, w.drain(&buf))
+		case <-ticker.C:
+			errs = multierr.Append(errs, w.flush(&buf))
+		case reply := <-w.syncReqs:
+			errs = multierr.Append(errs, w.drain(&buf))
+			errs = multierr.Append(errs, w.flush(&buf))
+			reply <- multierr.Append(errs, w.ws.Sync())
+			errs = nil
+		case <-w.stop:
+			errs = multierr.Append(errs, w.drain(&buf))
+			errs = multierr.Append(errs, w.flush(&buf))
+			w.closeErr = multierr.Append(errs, w.ws.Sync())
+			return
+		}
+	}
+}
+
+// drain moves all queued writes into buf, flushing buf whenever it reaches
+// the flush size.
+func (w *AsyncWriter) drain(buf *bytes.Buffer) error {
+	w.mu.Lock()
+	queue := w.queue
+	w.queue = nil
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+	var errs error
+	for _, b := range queue {
+		buf.Write(b)
+		if buf.Len() >= w.flushSize {
+			errs = multierr.Append(errs, w.flush(buf))
+		}
+	}
+	return errs
+}
+
+// flush writes buf to the wrapped WriteSyncer.
+func (w *AsyncWriter) flush(buf *bytes.Buffer) error {
+	if buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ws.Write(buf.Bytes())
+	buf.Reset()
+	return err
+}
+
+// OverflowPolicy determines what happens to entries written to an
+// AsyncWriter with a full queue.
+type OverflowPolicy int
+
+const (
+	// BlockOverflow blocks writing until there is space in the queue.
+	BlockOverflow OverflowPolicy = iota
+	// DropNewestOverflow drops the entry being written.
+	DropNewestOverflow
+	// DropOldestOverflow drops the oldest queued entry to make space for the
+	// entry being written.
+	DropOldestOverflow
+)
+
+func (p OverflowPolicy) MarshalText() ([]byte, error) { return []byte(p.String()), nil }
+
+// UnmarshalText unmarshals text to an overflow policy.
+// In particular, this makes it easy to configure overflow policies using
+// YAML, TOML, or JSON files.
+func (p *OverflowPolicy) UnmarshalText(text []byte) error {
+	if p == nil {
+		return errors.New("can't unmarshal a nil *OverflowPolicy")
+	}
+	if !p.unmarshalText(text) && !p.unmarshalText(bytes.ToLower(text)) {
+		return fmt.Errorf("unrecognised OverflowPolicy: %q", text)
+	}
+	return nil
+}
+
+func (p *OverflowPolicy) unmarshalText(text []byte) bool {
+	switch string(text) {
+	case "block", "":
+		*p = BlockOverflow
+	case "drop_newest", "drop-newest":
+		*p = DropNewestOverflow
+	case "drop_oldest", "drop-oldest":
+		*p = DropOldestOverflow
+	default:
+		return false
+	}
+	return true
+}
+
+// String returns a lower-case ASCII representation of the overflow policy
+func (p OverflowPolicy) String() string {
+	switch p {
+	case BlockOverflow:
+		return "block"
+	case DropNewestOverflow:
+		return "drop_newest"
+	case DropOldestOverflow:
+		return "drop_oldest"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", p)
+	}
+}
@@ -0,0 +1,118 @@
+package zaplogi
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// blockingWriter blocks every write until release is closed, signalling
+// started on the first write.
+type blockingWriter struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) Sync() error { return nil }
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriterOverflow(t *testing.T) {
+	tests := []struct {
+		overflow OverflowPolicy
+		want     string
+	}{
+		{overflow: DropNewestOverflow, want: "abc"},
+		{overflow: DropOldestOverflow, want: "acd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.overflow.String(), func(t *testing.T) {
+			bw := newBlockingWriter()
+			w := NewAsyncWriter(bw, AsyncConfig{QueueSize: 2, Overflow: tt.overflow})
+			defer w.Close()
+			w.Write([]byte("a"))
+			// Wait for "a" to be dequeued so that the queue is empty.
+			<-bw.started
+			for _, p := range []string{"b", "c", "d"} {
+				if _, err := w.Write([]byte(p)); err != nil {
+					t.Fatalf("Write(%q): %v", p, err)
+				}
+			}
+			stats := w.Stats()
+			if stats.QueueDepth != 2 || stats.QueueSize != 2 || stats.Dropped != 1 {
+				t.Errorf("Stats() = %+v, want depth 2, size 2 and 1 dropped", stats)
+			}
+			close(bw.release)
+			if err := w.Sync(); err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+			if got := bw.String(); got != tt.want {
+				t.Errorf("written %q, want %q", got, tt.want)
+			}
+			if depth := w.Stats().QueueDepth; depth != 0 {
+				t.Errorf("QueueDepth after Sync = %d, want 0", depth)
+			}
+		})
+	}
+}
+
+func TestAsyncWriterClose(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewAsyncWriter(zapcore.AddSync(&buf), AsyncConfig{FlushSize: 1 << 10})
+	w.Write([]byte("buffered"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := buf.String(); got != "buffered" {
+		t.Errorf("written %q after Close, want %q", got, "buffered")
+	}
+	if _, err := w.Write([]byte("late")); err == nil {
+		t.Error("Write after Close: got nil error")
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+// failingWriter fails every write and sync.
+type failingWriter struct{}
+
+var (
+	errWrite = errors.New("write failed")
+	errSync  = errors.New("sync failed")
+)
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errWrite }
+
+func (failingWriter) Sync() error { return errSync }
+
+func TestAsyncWriterCloseErrors(t *testing.T) {
+	w := NewAsyncWriter(failingWriter{}, AsyncConfig{FlushSize: 1 << 10})
+	w.Write([]byte("buffered"))
+	err := w.Close()
+	if !errors.Is(err, errWrite) || !errors.Is(err, errSync) {
+		t.Errorf("Close: got %v, want the write and sync errors", err)
+	}
+}
@@ -6,48 +6,157 @@ import (
 	"fmt"
 	"io"
 
-	"github.com/lohvht/logfeller"
 	"github.com/pkg/errors"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // LogConfig encapsulates the initialisation of the zap logger
 type LogConfig struct {
-	// ConsoleLog determines if you want to log to the console.
-	ConsoleLog     bool `json:"console_log" yaml:"console-log"`
-	RootCallerSkip int  `json:"root_caller_skip" yaml:"root-caller-skip"`
+	// ConsoleLog determines if you want to log to the console. Debug and info
+	// levels are logged to stdout and the rest to stderr in development mode,
+	// with levels coloured when logging to a terminal.
+	//
+	// Deprecated: Use Console instead, which takes precedence if set.
+	ConsoleLog bool `json:"console_log" toml:"console_log" yaml:"console-log"`
+	// ConsoleLogEncoding is the encoding used when logging to the console via
+	// ConsoleLog. Defaults to "console".
+	//
+	// Deprecated: Use Console instead.
+	ConsoleLogEncoding Encoding `json:"console_log_encoding" toml:"console_log_encoding" yaml:"console-log-encoding"`
+	// Console configures logging to the console. If set, the logger logs to
+	// the console regardless of ConsoleLog.
+	Console        *ConsoleConfig `json:"console,omitempty" toml:"console,omitempty" yaml:"console,omitempty"`
+	RootCallerSkip int            `json:"root_caller_skip" toml:"root_caller_skip" yaml:"root-caller-skip"`
+	// EncoderConfig customises how entries are encoded for all sinks. Each
+	// LogFileConfig may override parts of it via its own EncoderConfig.
+	EncoderConfig EncoderConfig `json:"encoder_config" toml:"encoder_config" yaml:"encoder-config"`
+	// LoggerLevels sets the minimum level of logger names, including their
+	// descendants. e.g. {"db": "warn"} drops entries below warn from "db" and
+	// "db.pool", on top of the level ranges of the sinks. The most specific
+	// logger name applies, so {"db": "warn", "db.pool": "debug"} still logs
+	// debug entries from "db.pool". The empty name is the root logger, which
+	// applies to all logger names as the least specific entry, so
+	// {"": "info", "db": "debug"} logs debug entries from "db" only.
+	LoggerLevels map[string]Level `json:"logger_levels,omitempty" toml:"logger_levels,omitempty" yaml:"logger-levels,omitempty"`
+	// Sampling limits how many entries are logged to each sink, including the
+	// console. Each LogFileConfig may replace it via its own Sampling.
+	Sampling *SamplingConfig `json:"sampling,omitempty" toml:"sampling,omitempty" yaml:"sampling,omitempty"`
+	// Redaction redacts sensitive values logged in key-value pairs.
+	Redaction *RedactionConfig `json:"redaction,omitempty" toml:"redaction,omitempty" yaml:"redaction,omitempty"`
 	// LogFileConfigs contain the various rotational file configurations
-	LogFileConfigs []LogFileConfig `json:"log_file_configs" yaml:"log-file-configs"`
+	LogFileConfigs []LogFileConfig `json:"log_file_configs" toml:"log_file_configs" yaml:"log-file-configs"`
 }
 
 // LogFileConfig is the configuration for the log file
-// If unmarshalling directly from JSON/YAML, take note
+// If unmarshalling directly from JSON/YAML/TOML, take note
 type LogFileConfig struct {
 	// LoggerName is the logger's name to log to. If specified, It will only
-	// log to this file when the logger's name is equal to LoggerName.
+	// log to this file when the logger's name is equal to LoggerName, or is a
+	// descendant of LoggerName, e.g. "db.pool" and "db.pool.conn" for "db".
+	// A descendant is only logged to the file(s) of its most specific
+	// LoggerName, so with files for both "db" and "db.pool", entries from
+	// "db.pool" are not logged to the "db" file.
+	// Entries of logger names that are not claimed by any LoggerName are
+	// logged to the files without a LoggerName.
 	// This gives fine control over which files that you want to log to.
 	LoggerName string
+	// LoggerNames are additional logger name patterns to log to, which may be
+	// logger names like LoggerName, globs such as "grpc.*" where "*" matches
+	// any characters (including ".") and "?" matches a single character, or
+	// regular expressions enclosed in slashes such as "/grpc\.(health|admin)/",
+	// which must match the whole logger name.
+	// If multiple files' patterns match a logger name, the most specific wins:
+	// deeper logger names are more specific, globs are more specific than the
+	// logger name of their leading literal segments (e.g. "grpc.*" over
+	// "grpc", but not over "grpc.health"), and regular expressions are the
+	// least specific.
+	LoggerNames []string
+	// ExcludeLoggerNames are logger name patterns that are never logged to
+	// this file, even if they match LoggerName or LoggerNames. They take the
+	// same form as LoggerNames.
+	ExcludeLoggerNames []string
 	// LogRange is the level range to log under. If not specified,
 	// defaul to [InfoLevel, InfoLevel]
 	LogRange [2]Level
 	// Type determines what type of log file to use. If specified,
 	// accepts "lumberjack" or "logfeller" as the 2 main log file handler configs
-	// to marshal as attributes via the `file_handler` field.
+	// to marshal as attributes via the `file_handler` field. "file" writes to
+	// a File that is not rotated, while "stdout" and "stderr" write to the
+	// respective streams and take no `file_handler`. Other types may be
+	// registered via RegisterWriterType.
 	// Check the respective docs on usage via JSON/YAML marshalling
 	// 	- `https://pkg.go.dev/github.com/lohvht/logfeller@v1.0.0`
 	// 	- `https://pkg.go.dev/gopkg.in/natefinch/lumberjack.v2`
 	// Otherwise, no io.Writer will be configured. Omit this field if you would
 	// like to set the writer manually.
 	Type LogFileType
+	// Encoding is the encoding used to write to the log file, either "console"
+	// or "json". Defaults to "console".
+	Encoding Encoding
+	// EncoderConfig overrides the non-zero fields of LogConfig.EncoderConfig
+	// for the log file.
+	EncoderConfig EncoderConfig
+	// Sampling limits how many entries are logged to the log file. If set, it
+	// replaces LogConfig.Sampling for the log file.
+	Sampling *SamplingConfig
+	// RateLimit caps the entries and bytes written to the log file per
+	// second.
+	RateLimit *RateLimitConfig
+	// Async writes to the log file asynchronously if set, so that logging
+	// does not block on a slow writer. Sync waits for the queued entries to
+	// be written.
+	Async *AsyncConfig
 	io.Writer
 }
 
 // logFileConfigJSON is the actual struct to marshal JSON to.
 type logFileConfigJSON struct {
-	LoggerName  string          `json:"logger_name"`
-	LogRange    [2]Level        `json:"log_range"`
-	Type        LogFileType     `json:"type"`
-	FileHandler json.RawMessage `json:"file_handler"`
+	LoggerName         string           `json:"logger_name"`
+	LoggerNames        []string         `json:"logger_names,omitempty"`
+	ExcludeLoggerNames []string         `json:"exclude_logger_names,omitempty"`
+	LogRange           [2]Level         `json:"log_range"`
+	Type               LogFileType      `json:"type"`
+	Encoding           Encoding         `json:"encoding"`
+	EncoderConfig      EncoderConfig    `json:"encoder_config"`
+	Sampling           *SamplingConfig  `json:"sampling,omitempty"`
+	RateLimit          *RateLimitConfig `json:"rate_limit,omitempty"`
+	Async              *AsyncConfig     `json:"async,omitempty"`
+	FileHandler        json.RawMessage  `json:"file_handler,omitempty"`
+}
+
+// writerType returns the type of the log file config. If Type is not set, the
+// type is inferred from the writer instead. This allows writers set manually
+// to be marshalled as well.
+func (c LogFileConfig) writerType() LogFileType {
+	if c.Type != NoWriter {
+		return c.Type
+	}
+	return writerTypeOf(c.Writer)
+}
+
+// MarshalJSON marshals the log file config to the same schema that
+// UnmarshalJSON accepts. The writer is marshalled as the `file_handler` field,
+// unless it is of a writer type that cannot be marshalled.
+func (c LogFileConfig) MarshalJSON() ([]byte, error) {
+	lfc := logFileConfigJSON{
+		LoggerName:         c.LoggerName,
+		LoggerNames:        c.LoggerNames,
+		ExcludeLoggerNames: c.ExcludeLoggerNames,
+		LogRange:           c.LogRange,
+		Type:               c.writerType(),
+		Encoding:           c.Encoding,
+		EncoderConfig:      c.EncoderConfig,
+		Sampling:           c.Sampling,
+		RateLimit:          c.RateLimit,
+		Async:              c.Async,
+	}
+	if lfc.Type != NoWriter && c.Writer != nil {
+		fileHandler, err := json.Marshal(c.Writer)
+		if err != nil {
+			return nil, err
+		}
+		lfc.FileHandler = fileHandler
+	}
+	return json.Marshal(lfc)
 }
 
 func (c *LogFileConfig) UnmarshalJSON(data []byte) error {
@@ -57,41 +166,102 @@ func (c *LogFileConfig) UnmarshalJSON(data []byte) error {
 		return err
 	}
 	c.LoggerName = lfc.LoggerName
+	c.LoggerNames = lfc.LoggerNames
+	c.ExcludeLoggerNames = lfc.ExcludeLoggerNames
 	c.LogRange = lfc.LogRange
 	c.Type = lfc.Type
-	switch c.Type {
-	case NoWriter:
+	c.Encoding = lfc.Encoding
+	c.EncoderConfig = lfc.EncoderConfig
+	c.Sampling = lfc.Sampling
+	c.RateLimit = lfc.RateLimit
+	c.Async = lfc.Async
+	if c.Type == NoWriter {
 		return nil
-	case Lumberjack:
-		if c.Writer != nil {
-			return fmt.Errorf("writer was already set for log file config; loggername=%q, logrange=%s, type=%q", c.LoggerName, c.LogRange, c.Type)
-		}
-		c.Writer = &lumberjack.Logger{}
-		return json.Unmarshal(lfc.FileHandler, &c.Writer)
-	case Logfeller:
-		if c.Writer != nil {
-			return fmt.Errorf("writer was already set for log file config; loggername=%q, logrange=%s, type=%q", c.LoggerName, c.LogRange, c.Type)
+	}
+	w, err := c.newWriter()
+	if err != nil {
+		return err
+	}
+	if len(lfc.FileHandler) > 0 {
+		err = json.Unmarshal(lfc.FileHandler, w)
+		if err != nil {
+			return err
 		}
-		c.Writer = &logfeller.File{}
-		return json.Unmarshal(lfc.FileHandler, &c.Writer)
-	default:
-		return fmt.Errorf("invalid type: %q", c.Type)
 	}
+	c.Writer = w
+	return nil
+}
+
+// newWriter returns a new writer of the config's type for the file handler
+// to be decoded into.
+func (c *LogFileConfig) newWriter() (io.Writer, error) {
+	if c.Writer != nil {
+		return nil, fmt.Errorf("writer was already set for log file config; loggername=%q, logrange=%s, type=%q", c.LoggerName, c.LogRange, c.Type)
+	}
+	newWriter, ok := c.Type.newWriterFunc()
+	if !ok {
+		return nil, fmt.Errorf("invalid type: %q", c.Type)
+	}
+	return newWriter(), nil
 }
 
 // logFileConfigYAMLBase is the actual struct to marshal the base YAML to.
 type logFileConfigYAMLBase struct {
-	LoggerName string      `yaml:"logger-name"`
-	LogRange   [2]Level    `yaml:"log-range"`
-	Type       LogFileType `yaml:"type"`
+	LoggerName         string           `yaml:"logger-name"`
+	LoggerNames        []string         `yaml:"logger-names,omitempty"`
+	ExcludeLoggerNames []string         `yaml:"exclude-logger-names,omitempty"`
+	LogRange           [2]Level         `yaml:"log-range"`
+	Type               LogFileType      `yaml:"type"`
+	Encoding           Encoding         `yaml:"encoding"`
+	EncoderConfig      EncoderConfig    `yaml:"encoder-config"`
+	Sampling           *SamplingConfig  `yaml:"sampling,omitempty"`
+	RateLimit          *RateLimitConfig `yaml:"rate-limit,omitempty"`
+	Async              *AsyncConfig     `yaml:"async,omitempty"`
 }
 
-type logFileConfigFileHandlerLumberjack struct {
-	FileHandler lumberjack.Logger `yaml:"file-handler"`
+// logFileConfigYAML is the actual struct to marshal YAML from.
+type logFileConfigYAML struct {
+	logFileConfigYAMLBase `yaml:",inline"`
+	FileHandler           io.Writer `yaml:"file-handler,omitempty"`
 }
 
-type logFileConfigFileHandlerLogfeller struct {
-	FileHandler logfeller.File `yaml:"file-handler"`
+// MarshalYAML marshals the log file config to the same schema that
+// UnmarshalYAML accepts. The writer is marshalled as the `file-handler` field,
+// unless it is of a writer type that cannot be marshalled.
+func (c LogFileConfig) MarshalYAML() (interface{}, error) {
+	lfc := logFileConfigYAML{
+		logFileConfigYAMLBase: logFileConfigYAMLBase{
+			LoggerName:         c.LoggerName,
+			LoggerNames:        c.LoggerNames,
+			ExcludeLoggerNames: c.ExcludeLoggerNames,
+			LogRange:           c.LogRange,
+			Type:               c.writerType(),
+			Encoding:           c.Encoding,
+			EncoderConfig:      c.EncoderConfig,
+			Sampling:           c.Sampling,
+			RateLimit:          c.RateLimit,
+			Async:              c.Async,
+		},
+	}
+	if lfc.Type != NoWriter {
+		lfc.FileHandler = c.Writer
+	}
+	return lfc, nil
+}
+
+// yamlFileHandler captures the `file-handler` node so that it can be decoded
+// once the type of the writer is known.
+type yamlFileHandler struct {
+	unmarshal func(interface{}) error
+}
+
+func (h *yamlFileHandler) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	h.unmarshal = unmarshal
+	return nil
+}
+
+type logFileConfigFileHandlerYAML struct {
+	FileHandler yamlFileHandler `yaml:"file-handler"`
 }
 
 func (c *LogFileConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -101,84 +271,93 @@ func (c *LogFileConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 	c.LoggerName = lfc.LoggerName
+	c.LoggerNames = lfc.LoggerNames
+	c.ExcludeLoggerNames = lfc.ExcludeLoggerNames
 	c.LogRange = lfc.LogRange
 	c.Type = lfc.Type
-	switch c.Type {
-	case NoWriter:
+	c.Encoding = lfc.Encoding
+	c.EncoderConfig = lfc.EncoderConfig
+	c.Sampling = lfc.Sampling
+	c.RateLimit = lfc.RateLimit
+	c.Async = lfc.Async
+	if c.Type == NoWriter {
 		return nil
-	case Lumberjack:
-		if c.Writer != nil {
-			return fmt.Errorf("writer was already set for log file config; loggername=%q, logrange=%s, type=%q", c.LoggerName, c.LogRange, c.Type)
-		}
-		var lj logFileConfigFileHandlerLumberjack
-		err := unmarshal(&lj)
-		if err != nil {
-			return err
-		}
-		c.Writer = &lj.FileHandler
-	case Logfeller:
-		if c.Writer != nil {
-			return fmt.Errorf("writer was already set for log file config; loggername=%q, logrange=%s, type=%q", c.LoggerName, c.LogRange, c.Type)
-		}
-		var lf logFileConfigFileHandlerLogfeller
-		err := unmarshal(&lf)
+	}
+	w, err := c.newWriter()
+	if err != nil {
+		return err
+	}
+	var fh logFileConfigFileHandlerYAML
+	err = unmarshal(&fh)
+	if err != nil {
+		return err
+	}
+	if fh.FileHandler.unmarshal != nil {
+		err = fh.FileHandler.unmarshal(w)
 		if err != nil {
 			return err
 		}
-		c.Writer = &lf.FileHandler
-	default:
-		return fmt.Errorf("invalid type: %q", c.Type)
 	}
+	c.Writer = w
 	return nil
 }
 
-type LogFileType int
+// UnmarshalTOML implements toml.Unmarshaler. The TOML schema of a log file
+// config is the same as its JSON schema, so the decoded TOML table is
+// unmarshalled the same way as JSON is.
+func (c *LogFileConfig) UnmarshalTOML(data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return c.UnmarshalJSON(b)
+}
+
+// Encoding determines the format that log entries are written in.
+type Encoding int
 
 const (
-	NoWriter LogFileType = iota
-	Lumberjack
-	Logfeller
+	// ConsoleEncoding writes entries in a human readable, tab separated format.
+	ConsoleEncoding Encoding = iota
+	// JSONEncoding writes entries as newline delimited JSON.
+	JSONEncoding
 )
 
-func (t LogFileType) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+func (e Encoding) MarshalText() ([]byte, error) { return []byte(e.String()), nil }
 
-// UnmarshalText unmarshals text to a log file type.
-// In particular, this makes it easy to configure log file types using YAML,
+// UnmarshalText unmarshals text to an encoding.
+// In particular, this makes it easy to configure encodings using YAML,
 // TOML, or JSON files.
-func (t *LogFileType) UnmarshalText(text []byte) error {
-	if t == nil {
-		return errors.New("can't unmarshal a nil *LogFileType")
+func (e *Encoding) UnmarshalText(text []byte) error {
+	if e == nil {
+		return errors.New("can't unmarshal a nil *Encoding")
 	}
-	if !t.unmarshalText(text) && !t.unmarshalText(bytes.ToLower(text)) {
-		return fmt.Errorf("unrecognised LogFileType: %q", text)
+	if !e.unmarshalText(text) && !e.unmarshalText(bytes.ToLower(text)) {
+		return fmt.Errorf("unrecognised Encoding: %q", text)
 	}
 	return nil
 }
 
-func (t *LogFileType) unmarshalText(text []byte) bool {
+func (e *Encoding) unmarshalText(text []byte) bool {
 	switch string(text) {
-	case "Lumberjack", "lumberjack", "lj":
-		*t = Lumberjack
-	case "Logfeller", "logfeller", "lf":
-		*t = Logfeller
-	case "":
-		*t = NoWriter
+	case "console", "":
+		*e = ConsoleEncoding
+	case "json":
+		*e = JSONEncoding
 	default:
 		return false
 	}
 	return true
 }
 
-// String returns a lower-case ASCII representation of the log file type
-func (t LogFileType) String() string {
-	switch t {
-	case Lumberjack:
-		return "lumberjack"
-	case Logfeller:
-		return "logfeller"
-	case NoWriter:
-		return "noWriter"
+// String returns a lower-case ASCII representation of the encoding
+func (e Encoding) String() string {
+	switch e {
+	case ConsoleEncoding:
+		return "console"
+	case JSONEncoding:
+		return "json"
 	default:
-		return fmt.Sprintf("LogFileType(%d)", t)
+		return fmt.Sprintf("Encoding(%d)", e)
 	}
 }
@@ -0,0 +1,305 @@
+package zaplogi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ConsoleConfig is the configuration for logging to the console. Fields left
+// out when unmarshalling take their values from DefaultConsoleConfig, so
+// start from DefaultConsoleConfig when creating a ConsoleConfig in code.
+type ConsoleConfig struct {
+	// Stream determines which stream(s) to log to, either "split" between
+	// stdout and stderr, "stdout" or "stderr". Defaults to "split".
+	Stream ConsoleStream `json:"stream" toml:"stream" yaml:"stream"`
+	// SplitLevel is the lowest level that is logged to stderr instead of
+	// stdout when Stream is "split". Defaults to warn.
+	SplitLevel Level `json:"split_level" toml:"split_level" yaml:"split-level"`
+	// LogRange is the level range to log under. Defaults to [debug, fatal].
+	LogRange [2]Level `json:"log_range" toml:"log_range" yaml:"log-range"`
+	// Colour determines if levels are coloured, either "auto", "on" or "off".
+	// "auto" colours levels only when logging to a terminal, unless overridden
+	// by the NO_COLOR or FORCE_COLOR environment variables. Defaults to
+	// "auto". An explicit EncoderConfig.LevelFormat always takes precedence.
+	Colour ColourMode `json:"colour" toml:"colour" yaml:"colour"`
+	// Encoding is the encoding used when logging to the console.
+	// Defaults to "console".
+	Encoding Encoding `json:"encoding" toml:"encoding" yaml:"encoding"`
+	// EncoderConfig overrides the non-zero fields of LogConfig.EncoderConfig
+	// for the console.
+	EncoderConfig EncoderConfig `json:"encoder_config" toml:"encoder_config" yaml:"encoder-config"`
+	// Development puts the whole logger in development mode, where DPanic
+	// level entries panic.
+	Development bool `json:"development" toml:"development" yaml:"development"`
+	// Stacktrace adds stack traces to warn level entries and above, for all
+	// sinks of the logger.
+	Stacktrace bool `json:"stacktrace" toml:"stacktrace" yaml:"stacktrace"`
+}
+
+// DefaultConsoleConfig returns the default console configuration.
+func DefaultConsoleConfig() ConsoleConfig {
+	return ConsoleConfig{
+		Stream:     SplitStream,
+		SplitLevel: WarnLevel,
+		LogRange:   [2]Level{MinLevel, MaxLevel},
+		Colour:     AutoColour,
+		Encoding:   ConsoleEncoding,
+	}
+}
+
+// consoleConfig has the same fields as ConsoleConfig, without its methods.
+type consoleConfig ConsoleConfig
+
+func (c *ConsoleConfig) UnmarshalJSON(data []byte) error {
+	cc := consoleConfig(DefaultConsoleConfig())
+	err := json.Unmarshal(data, &cc)
+	if err != nil {
+		return err
+	}
+	*c = ConsoleConfig(cc)
+	return nil
+}
+
+func (c *ConsoleConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	cc := consoleConfig(DefaultConsoleConfig())
+	err := unmarshal(&cc)
+	if err != nil {
+		return err
+	}
+	*c = ConsoleConfig(cc)
+	return nil
+}
+
+// UnmarshalTOML implements toml.Unmarshaler. The TOML schema of a console
+// config is the same as its JSON schema.
+func (c *ConsoleConfig) UnmarshalTOML(data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return c.UnmarshalJSON(b)
+}
+
+// consoleConfig returns the console configuration of c, and whether logging
+// to the console is enabled at all.
+func (c LogConfig) consoleConfig() (ConsoleConfig, bool) {
+	if c.Console != nil {
+		return *c.Console, true
+	}
+	if !c.ConsoleLog {
+		return ConsoleConfig{}, false
+	}
+	cc := DefaultConsoleConfig()
+	cc.Encoding = c.ConsoleLogEncoding
+	cc.Development = true
+	return cc, true
+}
+
+// consoleStream is a stream that the console logs to, along with the
+// levels logged to it.
+type consoleStream struct {
+	file    *os.File
+	enabled func(zapcore.Level) bool
+}
+
+// newConsoleCores returns the cores logging to the console, the console sink,
+// the zap options required and the closers of the cores.
+func newConsoleCores(cc ConsoleConfig, encConf EncoderConfig, sampling *SamplingConfig, red *redactor, r *router) ([]zapcore.Core, Sink, []zap.Option, []io.Closer, error) {
+	consoleLevel, err := NewAtomicLevelRange(cc.LogRange)
+	if err != nil {
+		return nil, Sink{}, nil, nil, err
+	}
+	var streams []consoleStream
+	switch cc.Stream {
+	case SplitStream:
+		splitLevel := cc.SplitLevel
+		streams = []consoleStream{
+			{file: os.Stdout, enabled: func(lvl zapcore.Level) bool { return !Level(lvl).AtLeast(splitLevel) }},
+			{file: os.Stderr, enabled: func(lvl zapcore.Level) bool { return Level(lvl).AtLeast(splitLevel) }},
+		}
+	case StdoutStream:
+		streams = []consoleStream{{file: os.Stdout, enabled: func(zapcore.Level) bool { return true }}}
+	case StderrStream:
+		streams = []consoleStream{{file: os.Stderr, enabled: func(zapcore.Level) bool { return true }}}
+	default:
+		return nil, Sink{}, nil, nil, fmt.Errorf("invalid console stream: %q", cc.Stream)
+	}
+	encConf = encConf.merge(cc.EncoderConfig)
+	var cores []zapcore.Core
+	var closers []io.Closer
+	for _, s := range streams {
+		zapEncConf, err := encConf.build(cc.Encoding == ConsoleEncoding && cc.Colour.enabled(s.file))
+		if err != nil {
+			return nil, Sink{}, nil, nil, err
+		}
+		enc, err := newEncoder(cc.Encoding, zapEncConf)
+		if err != nil {
+			return nil, Sink{}, nil, nil, err
+		}
+		streamEnabled := s.enabled
+		priority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+			return streamEnabled(lvl) && consoleLevel.Enabled(lvl)
+		})
+		core, closer := sampling.wrap(red.wrap(zapcore.NewCore(enc, zapcore.Lock(s.file), priority)))
+		cores = append(cores, newRoutedCore(r, consoleSink, core))
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+	}
+	var options []zap.Option
+	if cc.Development {
+		options = append(options, zap.Development())
+	}
+	if cc.Stacktrace {
+		stacktrace := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool { return Level(lvl).AtLeast(WarnLevel) })
+		options = append(options, zap.AddStacktrace(stacktrace))
+	}
+	return cores, Sink{Name: ConsoleSinkName, Level: consoleLevel, route: consoleSink}, options, closers, nil
+}
+
+// isTerminal returns true if f is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// ConsoleStream determines which stream(s) the console logs to.
+type ConsoleStream int
+
+const (
+	// SplitStream logs entries below ConsoleConfig.SplitLevel to stdout, and
+	// the rest to stderr.
+	SplitStream ConsoleStream = iota
+	// StdoutStream logs all entries to stdout.
+	StdoutStream
+	// StderrStream logs all entries to stderr.
+	StderrStream
+)
+
+func (s ConsoleStream) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// UnmarshalText unmarshals text to a console stream.
+// In particular, this makes it easy to configure console streams using YAML,
+// TOML, or JSON files.
+func (s *ConsoleStream) UnmarshalText(text []byte) error {
+	if s == nil {
+		return errors.New("can't unmarshal a nil *ConsoleStream")
+	}
+	if !s.unmarshalText(text) && !s.unmarshalText(bytes.ToLower(text)) {
+		return fmt.Errorf("unrecognised ConsoleStream: %q", text)
+	}
+	return nil
+}
+
+func (s *ConsoleStream) unmarshalText(text []byte) bool {
+	switch string(text) {
+	case "split", "":
+		*s = SplitStream
+	case "stdout":
+		*s = StdoutStream
+	case "stderr":
+		*s = StderrStream
+	default:
+		return false
+	}
+	return true
+}
+
+// String returns a lower-case ASCII representation of the console stream
+func (s ConsoleStream) String() string {
+	switch s {
+	case SplitStream:
+		return "split"
+	case StdoutStream:
+		return "stdout"
+	case StderrStream:
+		return "stderr"
+	default:
+		return fmt.Sprintf("ConsoleStream(%d)", s)
+	}
+}
+
+// ColourMode determines if levels logged to the console are coloured.
+type ColourMode int
+
+const (
+	// AutoColour colours levels only when logging to a terminal. The NO_COLOR
+	// and FORCE_COLOR environment variables are honoured, see
+	// https://no-color.org and https://force-color.org.
+	AutoColour ColourMode = iota
+	// AlwaysColour always colours levels.
+	AlwaysColour
+	// NeverColour never colours levels.
+	NeverColour
+)
+
+// enabled returns true if levels logged to f should be coloured.
+func (m ColourMode) enabled(f *os.File) bool {
+	switch m {
+	case AlwaysColour:
+		return true
+	case NeverColour:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("FORCE_COLOR"); force != "" {
+		return force != "0" && !strings.EqualFold(force, "false")
+	}
+	return isTerminal(f)
+}
+
+func (m ColourMode) MarshalText() ([]byte, error) { return []byte(m.String()), nil }
+
+// UnmarshalText unmarshals text to a colour mode.
+// In particular, this makes it easy to configure colour modes using YAML,
+// TOML, or JSON files.
+func (m *ColourMode) UnmarshalText(text []byte) error {
+	if m == nil {
+		return errors.New("can't unmarshal a nil *ColourMode")
+	}
+	if !m.unmarshalText(text) && !m.unmarshalText(bytes.ToLower(text)) {
+		return fmt.Errorf("unrecognised ColourMode: %q", text)
+	}
+	return nil
+}
+
+func (m *ColourMode) unmarshalText(text []byte) bool {
+	switch string(text) {
+	case "auto", "":
+		*m = AutoColour
+	case "on", "always", "true", "yes":
+		*m = AlwaysColour
+	case "off", "never", "false", "no":
+		*m = NeverColour
+	default:
+		return false
+	}
+	return true
+}
+
+// String returns a lower-case ASCII representation of the colour mode
+func (m ColourMode) String() string {
+	switch m {
+	case AutoColour:
+		return "auto"
+	case AlwaysColour:
+		return "on"
+	case NeverColour:
+		return "off"
+	default:
+		return fmt.Sprintf("ColourMode(%d)", m)
+	}
+}
@@ -0,0 +1,68 @@
+package zaplogi
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestColourModeEnabled(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	tests := []struct {
+		name        string
+		mode        ColourMode
+		noColour    string
+		forceColour string
+		want        bool
+	}{
+		{name: "auto not a terminal", mode: AutoColour, want: false},
+		{name: "auto force", mode: AutoColour, forceColour: "1", want: true},
+		{name: "auto force disabled", mode: AutoColour, forceColour: "0", want: false},
+		{name: "auto force false", mode: AutoColour, forceColour: "false", want: false},
+		{name: "auto no colour wins over force", mode: AutoColour, noColour: "1", forceColour: "1", want: false},
+		{name: "on ignores no colour", mode: AlwaysColour, noColour: "1", want: true},
+		{name: "off ignores force", mode: NeverColour, forceColour: "1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColour)
+			t.Setenv("FORCE_COLOR", tt.forceColour)
+			if got := tt.mode.enabled(w); got != tt.want {
+				t.Errorf("enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsoleStacktrace(t *testing.T) {
+	legacy, ok := LogConfig{ConsoleLog: true}.consoleConfig()
+	if !ok || !legacy.Development || legacy.Stacktrace {
+		t.Errorf("legacy console config = %+v, want development without stack traces", legacy)
+	}
+	for _, stacktrace := range []bool{false, true} {
+		var buf bytes.Buffer
+		l, err := NewWithConfig(LogConfig{
+			// The console only logs fatal entries, to keep the test quiet.
+			Console:        &ConsoleConfig{Stream: StderrStream, LogRange: [2]Level{FatalLevel, FatalLevel}, Development: true, Stacktrace: stacktrace},
+			LogFileConfigs: []LogFileConfig{{LogRange: [2]Level{InfoLevel, MaxLevel}, Writer: &buf}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.Info("info")
+		if strings.Contains(buf.String(), "TestConsoleStacktrace") {
+			t.Errorf("info entry %q has a stack trace", buf.String())
+		}
+		buf.Reset()
+		l.Warn("warn")
+		if got := strings.Contains(buf.String(), "TestConsoleStacktrace"); got != stacktrace {
+			t.Errorf("Stacktrace %v: warn entry %q has a stack trace: %v", stacktrace, buf.String(), got)
+		}
+	}
+}
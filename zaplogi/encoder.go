@@ -0,0 +1,387 @@
+package zaplogi
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// OmitKey may be used as any of the keys in EncoderConfig to leave the
+// corresponding part of the entry out of the output.
+const OmitKey = "-"
+
+// EncoderConfig customises how log entries are encoded. Zero valued fields
+// are left as the default, which allows an EncoderConfig set on a
+// LogFileConfig to override only some of the fields of LogConfig.EncoderConfig.
+type EncoderConfig struct {
+	// Keys of the respective parts of an entry. Set a key to OmitKey to leave
+	// that part out of the output.
+	MessageKey    string `json:"message_key,omitempty" toml:"message_key,omitempty" yaml:"message-key,omitempty"`
+	LevelKey      string `json:"level_key,omitempty" toml:"level_key,omitempty" yaml:"level-key,omitempty"`
+	TimeKey       string `json:"time_key,omitempty" toml:"time_key,omitempty" yaml:"time-key,omitempty"`
+	NameKey       string `json:"name_key,omitempty" toml:"name_key,omitempty" yaml:"name-key,omitempty"`
+	CallerKey     string `json:"caller_key,omitempty" toml:"caller_key,omitempty" yaml:"caller-key,omitempty"`
+	StacktraceKey string `json:"stacktrace_key,omitempty" toml:"stacktrace_key,omitempty" yaml:"stacktrace-key,omitempty"`
+	// TimeFormat is one of "rfc3339", "rfc3339nano", "iso8601", "epoch",
+	// "epoch_millis" or "epoch_nanos". Any other value is used as a custom
+	// time layout, see time.Layout. Defaults to "2006-01-02 15:04:05.000Z0700".
+	TimeFormat string `json:"time_format,omitempty" toml:"time_format,omitempty" yaml:"time-format,omitempty"`
+	// LevelFormat determines how levels are written. Levels are coloured by
+	// default only when writing console encoded entries to the console.
+	LevelFormat LevelFormat `json:"level_format,omitempty" toml:"level_format,omitempty" yaml:"level-format,omitempty"`
+	// CallerFormat determines how callers are written. Defaults to "short".
+	CallerFormat CallerFormat `json:"caller_format,omitempty" toml:"caller_format,omitempty" yaml:"caller-format,omitempty"`
+	// DurationFormat determines how time.Duration fields are written.
+	// Defaults to "seconds".
+	DurationFormat DurationFormat `json:"duration_format,omitempty" toml:"duration_format,omitempty" yaml:"duration-format,omitempty"`
+}
+
+// merge returns a copy of c with the non-zero fields of override applied.
+func (c EncoderConfig) merge(override EncoderConfig) EncoderConfig {
+	mergeString := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	mergeString(&c.MessageKey, override.MessageKey)
+	mergeString(&c.LevelKey, override.LevelKey)
+	mergeString(&c.TimeKey, override.TimeKey)
+	mergeString(&c.NameKey, override.NameKey)
+	mergeString(&c.CallerKey, override.CallerKey)
+	mergeString(&c.StacktraceKey, override.StacktraceKey)
+	mergeString(&c.TimeFormat, override.TimeFormat)
+	if override.LevelFormat != DefaultLevelFormat {
+		c.LevelFormat = override.LevelFormat
+	}
+	if override.CallerFormat != DefaultCallerFormat {
+		c.CallerFormat = override.CallerFormat
+	}
+	if override.DurationFormat != DefaultDurationFormat {
+		c.DurationFormat = override.DurationFormat
+	}
+	return c
+}
+
+// build returns the zapcore.EncoderConfig, starting from defaultEncoderConfig.
+// colour determines whether levels are coloured when LevelFormat is left as
+// the default.
+func (c EncoderConfig) build(colour bool) (zapcore.EncoderConfig, error) {
+	encConf := defaultEncoderConfig()
+	setKey := func(dst *string, src string) {
+		switch src {
+		case "":
+		case OmitKey:
+			*dst = zapcore.OmitKey
+		default:
+			*dst = src
+		}
+	}
+	setKey(&encConf.MessageKey, c.MessageKey)
+	setKey(&encConf.LevelKey, c.LevelKey)
+	setKey(&encConf.TimeKey, c.TimeKey)
+	setKey(&encConf.NameKey, c.NameKey)
+	setKey(&encConf.CallerKey, c.CallerKey)
+	setKey(&encConf.StacktraceKey, c.StacktraceKey)
+
+	switch c.TimeFormat {
+	case "":
+	case "rfc3339":
+		encConf.EncodeTime = zapcore.RFC3339TimeEncoder
+	case "rfc3339nano":
+		encConf.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	case "iso8601":
+		encConf.EncodeTime = zapcore.ISO8601TimeEncoder
+	case "epoch":
+		encConf.EncodeTime = zapcore.EpochTimeEncoder
+	case "epoch_millis":
+		encConf.EncodeTime = zapcore.EpochMillisTimeEncoder
+	case "epoch_nanos":
+		encConf.EncodeTime = zapcore.EpochNanosTimeEncoder
+	default:
+		encConf.EncodeTime = zapcore.TimeEncoderOfLayout(c.TimeFormat)
+	}
+
+	switch c.LevelFormat {
+	case DefaultLevelFormat:
+		if !colour {
+			encConf.EncodeLevel = zapcore.CapitalLevelEncoder
+		}
+	case LowercaseLevelFormat:
+		encConf.EncodeLevel = zapcore.LowercaseLevelEncoder
+	case CapitalLevelFormat:
+		encConf.EncodeLevel = zapcore.CapitalLevelEncoder
+	case LowercaseColourLevelFormat:
+		encConf.EncodeLevel = zapcore.LowercaseColorLevelEncoder
+	case CapitalColourLevelFormat:
+		encConf.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	default:
+		return encConf, fmt.Errorf("invalid level format: %q", c.LevelFormat)
+	}
+	encConf.EncodeLevel = customLevelEncoder(encConf.EncodeLevel, c.LevelFormat)
+
+	switch c.CallerFormat {
+	case DefaultCallerFormat, ShortCallerFormat:
+	case FullCallerFormat:
+		encConf.EncodeCaller = zapcore.FullCallerEncoder
+	case DisabledCallerFormat:
+		encConf.CallerKey = zapcore.OmitKey
+	default:
+		return encConf, fmt.Errorf("invalid caller format: %q", c.CallerFormat)
+	}
+
+	switch c.DurationFormat {
+	case DefaultDurationFormat, SecondsDurationFormat:
+	case MillisDurationFormat:
+		encConf.EncodeDuration = zapcore.MillisDurationEncoder
+	case NanosDurationFormat:
+		encConf.EncodeDuration = zapcore.NanosDurationEncoder
+	case StringDurationFormat:
+		encConf.EncodeDuration = zapcore.StringDurationEncoder
+	default:
+		return encConf, fmt.Errorf("invalid duration format: %q", c.DurationFormat)
+	}
+	return encConf, nil
+}
+
+// customLevelEncoder wraps enc to encode custom levels by their names, which
+// zap does not know about. Custom levels are never coloured.
+func customLevelEncoder(enc zapcore.LevelEncoder, f LevelFormat) zapcore.LevelEncoder {
+	lowercase := f == LowercaseLevelFormat || f == LowercaseColourLevelFormat
+	return func(l zapcore.Level, pae zapcore.PrimitiveArrayEncoder) {
+		lvl := Level(l)
+		if !lvl.IsCustom() {
+			enc(l, pae)
+		} else if lowercase {
+			pae.AppendString(lvl.String())
+		} else {
+			pae.AppendString(lvl.CapitalString())
+		}
+	}
+}
+
+// defaultEncoderConfig returns the default encoding used. Note that EncodeLevel
+// is encoded in colour by default.
+func defaultEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		MessageKey: "msg",
+		TimeKey:    "timestamp",
+		EncodeTime: func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendString(t.Format("2006-01-02 15:04:05.000Z0700"))
+		},
+		LevelKey:       "level",
+		EncodeLevel:    zapcore.CapitalColorLevelEncoder,
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+		StacktraceKey:  "stacktrace",
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+		LineEnding:     zapcore.DefaultLineEnding,
+	}
+}
+
+// newEncoder returns the encoder for the given encoding.
+func newEncoder(e Encoding, encConf zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	switch e {
+	case ConsoleEncoding:
+		return zapcore.NewConsoleEncoder(encConf), nil
+	case JSONEncoding:
+		return zapcore.NewJSONEncoder(encConf), nil
+	default:
+		return nil, fmt.Errorf("invalid encoding: %q", e)
+	}
+}
+
+// LevelFormat determines how levels are written.
+type LevelFormat int
+
+const (
+	DefaultLevelFormat LevelFormat = iota
+	// LowercaseLevelFormat writes levels as e.g. "info".
+	LowercaseLevelFormat
+	// CapitalLevelFormat writes levels as e.g. "INFO".
+	CapitalLevelFormat
+	// LowercaseColourLevelFormat writes levels as e.g. "info" in colour.
+	LowercaseColourLevelFormat
+	// CapitalColourLevelFormat writes levels as e.g. "INFO" in colour.
+	CapitalColourLevelFormat
+)
+
+func (f LevelFormat) MarshalText() ([]byte, error) { return []byte(f.String()), nil }
+
+// UnmarshalText unmarshals text to a level format.
+// In particular, this makes it easy to configure level formats using YAML,
+// TOML, or JSON files.
+func (f *LevelFormat) UnmarshalText(text []byte) error {
+	if f == nil {
+		return errors.New("can't unmarshal a nil *LevelFormat")
+	}
+	if !f.unmarshalText(text) && !f.unmarshalText(bytes.ToLower(text)) {
+		return fmt.Errorf("unrecognised LevelFormat: %q", text)
+	}
+	return nil
+}
+
+func (f *LevelFormat) unmarshalText(text []byte) bool {
+	switch string(text) {
+	case "":
+		*f = DefaultLevelFormat
+	case "lower", "lowercase":
+		*f = LowercaseLevelFormat
+	case "capital":
+		*f = CapitalLevelFormat
+	case "lower_colour", "lower_color", "lowercase_colour", "lowercase_color":
+		*f = LowercaseColourLevelFormat
+	case "capital_colour", "capital_color", "colour", "color":
+		*f = CapitalColourLevelFormat
+	default:
+		return false
+	}
+	return true
+}
+
+// String returns a lower-case ASCII representation of the level format
+func (f LevelFormat) String() string {
+	switch f {
+	case DefaultLevelFormat:
+		return ""
+	case LowercaseLevelFormat:
+		return "lower"
+	case CapitalLevelFormat:
+		return "capital"
+	case LowercaseColourLevelFormat:
+		return "lower_colour"
+	case CapitalColourLevelFormat:
+		return "capital_colour"
+	default:
+		return fmt.Sprintf("LevelFormat(%d)", f)
+	}
+}
+
+// CallerFormat determines how callers are written.
+type CallerFormat int
+
+const (
+	DefaultCallerFormat CallerFormat = iota
+	// ShortCallerFormat writes callers as package/file:line.
+	ShortCallerFormat
+	// FullCallerFormat writes callers as /full/path/to/package/file:line.
+	FullCallerFormat
+	// DisabledCallerFormat leaves callers out of the output.
+	DisabledCallerFormat
+)
+
+func (f CallerFormat) MarshalText() ([]byte, error) { return []byte(f.String()), nil }
+
+// UnmarshalText unmarshals text to a caller format.
+// In particular, this makes it easy to configure caller formats using YAML,
+// TOML, or JSON files.
+func (f *CallerFormat) UnmarshalText(text []byte) error {
+	if f == nil {
+		return errors.New("can't unmarshal a nil *CallerFormat")
+	}
+	if !f.unmarshalText(text) && !f.unmarshalText(bytes.ToLower(text)) {
+		return fmt.Errorf("unrecognised CallerFormat: %q", text)
+	}
+	return nil
+}
+
+func (f *CallerFormat) unmarshalText(text []byte) bool {
+	switch string(text) {
+	case "":
+		*f = DefaultCallerFormat
+	case "short":
+		*f = ShortCallerFormat
+	case "full":
+		*f = FullCallerFormat
+	case "disabled", "none":
+		*f = DisabledCallerFormat
+	default:
+		return false
+	}
+	return true
+}
+
+// String returns a lower-case ASCII representation of the caller format
+func (f CallerFormat) String() string {
+	switch f {
+	case DefaultCallerFormat:
+		return ""
+	case ShortCallerFormat:
+		return "short"
+	case FullCallerFormat:
+		return "full"
+	case DisabledCallerFormat:
+		return "disabled"
+	default:
+		return fmt.Sprintf("CallerFormat(%d)", f)
+	}
+}
+
+// DurationFormat determines how time.Duration fields are written.
+type DurationFormat int
+
+const (
+	DefaultDurationFormat DurationFormat = iota
+	// SecondsDurationFormat writes durations as floating-point seconds.
+	SecondsDurationFormat
+	// MillisDurationFormat writes durations as floating-point milliseconds.
+	MillisDurationFormat
+	// NanosDurationFormat writes durations as integer nanoseconds.
+	NanosDurationFormat
+	// StringDurationFormat writes durations via time.Duration.String.
+	StringDurationFormat
+)
+
+func (f DurationFormat) MarshalText() ([]byte, error) { return []byte(f.String()), nil }
+
+// UnmarshalText unmarshals text to a duration format.
+// In particular, this makes it easy to configure duration formats using YAML,
+// TOML, or JSON files.
+func (f *DurationFormat) UnmarshalText(text []byte) error {
+	if f == nil {
+		return errors.New("can't unmarshal a nil *DurationFormat")
+	}
+	if !f.unmarshalText(text) && !f.unmarshalText(bytes.ToLower(text)) {
+		return fmt.Errorf("unrecognised DurationFormat: %q", text)
+	}
+	return nil
+}
+
+func (f *DurationFormat) unmarshalText(text []byte) bool {
+	switch string(text) {
+	case "":
+		*f = DefaultDurationFormat
+	case "seconds":
+		*f = SecondsDurationFormat
+	case "millis", "ms":
+		*f = MillisDurationFormat
+	case "nanos", "ns":
+		*f = NanosDurationFormat
+	case "string":
+		*f = StringDurationFormat
+	default:
+		return false
+	}
+	return true
+}
+
+// String returns a lower-case ASCII representation of the duration format
+func (f DurationFormat) String() string {
+	switch f {
+	case DefaultDurationFormat:
+		return ""
+	case SecondsDurationFormat:
+		return "seconds"
+	case MillisDurationFormat:
+		return "millis"
+	case NanosDurationFormat:
+		return "nanos"
+	case StringDurationFormat:
+		return "string"
+	default:
+		return fmt.Sprintf("DurationFormat(%d)", f)
+	}
+}
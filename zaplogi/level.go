@@ -1,102 +1,28 @@
 package zaplogi
 
-import (
-	"bytes"
-	"fmt"
+import "github.com/lohvht/logi/iface"
 
-	"github.com/pkg/errors"
-	"go.uber.org/zap/zapcore"
-)
-
-type Level zapcore.Level
+// Level is the severity of a log entry, see iface.Level.
+type Level = iface.Level
 
-// Log Levels, this section is taken directly from zapcore
+// Log Levels, see iface.Level.
 const (
-	// DebugLevel logs are typically voluminous, and are usually disabled in
-	// production.
-	DebugLevel = Level(zapcore.DebugLevel)
-	// InfoLevel is the default logging priority.
-	InfoLevel = Level(zapcore.InfoLevel)
-	// WarnLevel logs are more important than Info, but don't need individual
-	// human review.
-	WarnLevel = Level(zapcore.WarnLevel)
-	// ErrorLevel logs are high-priority. If an application is running smoothly,
-	// it shouldn't generate any error-level logs.
-	ErrorLevel = Level(zapcore.ErrorLevel)
-	// DPanicLevel logs are particularly important errors. In development the
-	// logger panics after writing the message.
-	DPanicLevel = Level(zapcore.DPanicLevel)
-	// PanicLevel logs a message, then panics.
-	PanicLevel = Level(zapcore.PanicLevel)
-	// FatalLevel logs a message, then calls os.Exit(1).
-	FatalLevel = Level(zapcore.FatalLevel)
-
-	MinLevel = DebugLevel
-	MaxLevel = FatalLevel
+	DebugLevel  = iface.DebugLevel
+	InfoLevel   = iface.InfoLevel
+	WarnLevel   = iface.WarnLevel
+	ErrorLevel  = iface.ErrorLevel
+	DPanicLevel = iface.DPanicLevel
+	PanicLevel  = iface.PanicLevel
+	FatalLevel  = iface.FatalLevel
+
+	MinLevel = iface.MinLevel
+	MaxLevel = iface.MaxLevel
+
+	TraceLevel  = iface.TraceLevel
+	NoticeLevel = iface.NoticeLevel
 )
 
-// MarshalText marshals the Level to text. Note that the text representation
-// drops the -Level suffix (see example).
-func (l Level) MarshalText() ([]byte, error) {
-	return []byte(l.String()), nil
-}
-
-// UnmarshalText unmarshals text to a level. Like MarshalText, UnmarshalText
-// expects the text representation of a Level to drop the -Level suffix (see
-// example).
-//
-// In particular, this makes it easy to configure logging levels using YAML,
-// TOML, or JSON files.
-func (l *Level) UnmarshalText(text []byte) error {
-	if l == nil {
-		return errors.New("can't unmarshal a nil *Level")
-	}
-	if !l.unmarshalText(text) && !l.unmarshalText(bytes.ToLower(text)) {
-		return fmt.Errorf("unrecognised level: %q", text)
-	}
-	return nil
-}
-
-func (l *Level) unmarshalText(text []byte) bool {
-	switch string(text) {
-	case "debug", "DEBUG", "min", "MIN":
-		*l = DebugLevel
-	case "info", "INFO", "": // make the zero value useful
-		*l = InfoLevel
-	case "warn", "WARN":
-		*l = WarnLevel
-	case "error", "ERROR":
-		*l = ErrorLevel
-	case "dpanic", "DPANIC":
-		*l = DPanicLevel
-	case "panic", "PANIC":
-		*l = PanicLevel
-	case "fatal", "FATAL", "max", "MAX":
-		*l = FatalLevel
-	default:
-		return false
-	}
-	return true
-}
-
-// String returns a lower-case ASCII representation of the log level.
-func (l Level) String() string {
-	switch l {
-	case DebugLevel:
-		return "debug"
-	case InfoLevel:
-		return "info"
-	case WarnLevel:
-		return "warn"
-	case ErrorLevel:
-		return "error"
-	case DPanicLevel:
-		return "dpanic"
-	case PanicLevel:
-		return "panic"
-	case FatalLevel:
-		return "fatal"
-	default:
-		return fmt.Sprintf("Level(%d)", l)
-	}
+// RegisterLevel registers a custom level, see iface.RegisterLevel.
+func RegisterLevel(name string, severity int) (Level, error) {
+	return iface.RegisterLevel(name, severity)
 }
@@ -0,0 +1,55 @@
+package levelhttp_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/lohvht/logi/zaplogi"
+	"github.com/lohvht/logi/zaplogi/levelhttp"
+)
+
+func ExampleHandler() {
+	logger, err := zaplogi.NewWithConfig(zaplogi.LogConfig{
+		LogFileConfigs: []zaplogi.LogFileConfig{
+			{LogRange: [2]zaplogi.Level{zaplogi.InfoLevel, zaplogi.MaxLevel}, Writer: io.Discard},
+			{LoggerName: "db", LogRange: [2]zaplogi.Level{zaplogi.WarnLevel, zaplogi.MaxLevel}, Writer: io.Discard},
+			{LoggerNames: []string{"grpc.*"}, LogRange: [2]zaplogi.Level{zaplogi.WarnLevel, zaplogi.MaxLevel}, Writer: io.Discard},
+		},
+	})
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		return
+	}
+	srv := httptest.NewServer(levelhttp.NewHandler(logger))
+	defer srv.Close()
+
+	do := func(method, body string) {
+		req, _ := http.NewRequest(method, srv.URL, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		fmt.Print(resp.StatusCode, " ", string(b))
+	}
+	do(http.MethodGet, "")
+	do(http.MethodPut, `{"logger_name": "db", "log_range": ["debug", "fatal"]}`)
+	do(http.MethodPut, `{"logger_name": "grpc.*", "log_range": ["info", "fatal"]}`)
+	do(http.MethodPut, `{"name": "file[0]", "log_range": ["error", "warn"]}`)
+	do(http.MethodPut, `{"name": "file[0]", "log_range": ["info", "notice"]}`)
+	do(http.MethodPut, `{"name": "file[9]", "log_range": ["debug", "fatal"]}`)
+	do(http.MethodGet, "")
+	// Output:
+	// 200 {"sinks":[{"name":"file[0]","log_range":["info","fatal"]},{"name":"file[1]","logger_name":"db","log_range":["warn","fatal"]},{"name":"file[2]","logger_names":["grpc.*"],"log_range":["warn","fatal"]}]}
+	// 200 {"sinks":[{"name":"file[1]","logger_name":"db","log_range":["debug","fatal"]}]}
+	// 200 {"sinks":[{"name":"file[2]","logger_names":["grpc.*"],"log_range":["info","fatal"]}]}
+	// 400 {"error":"log level high (warn) is smaller than low (error)"}
+	// 200 {"sinks":[{"name":"file[0]","log_range":["info","notice"]}]}
+	// 404 {"error":"no sink found; name=\"file[9]\", logger_name=\"\""}
+	// 200 {"sinks":[{"name":"file[0]","log_range":["info","notice"]},{"name":"file[1]","logger_name":"db","log_range":["debug","fatal"]},{"name":"file[2]","logger_names":["grpc.*"],"log_range":["info","fatal"]}]}
+}
@@ -0,0 +1,141 @@
+// levelhttp provides an http.Handler to inspect and change the level ranges of
+// a zaplogi.Logger's sinks at runtime.
+package levelhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/lohvht/logi/zaplogi"
+)
+
+// SinkLevel is the JSON representation of a sink's level range.
+type SinkLevel struct {
+	Name        string           `json:"name"`
+	LoggerName  string           `json:"logger_name,omitempty"`
+	LoggerNames []string         `json:"logger_names,omitempty"`
+	LogRange    [2]zaplogi.Level `json:"log_range"`
+}
+
+// SinkLevels is the response body of the handler.
+type SinkLevels struct {
+	Sinks []SinkLevel `json:"sinks"`
+}
+
+// UpdateRequest is the request body accepted by the handler on PUT. At least
+// one of Name or LoggerName must be set; every sink matching all of the set
+// selectors will have its level range changed to LogRange. LoggerName
+// selects the sinks whose logger name or one of whose logger name patterns
+// is exactly LoggerName, see zaplogi.Sink.
+type UpdateRequest struct {
+	Name       string           `json:"name"`
+	LoggerName string           `json:"logger_name"`
+	LogRange   [2]zaplogi.Level `json:"log_range"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler serves the level ranges of the sinks of a zaplogi.Logger.
+//   - GET lists every sink along with its current level range.
+//   - PUT changes the level range of the sinks selected by an UpdateRequest
+//     and responds with the updated sinks.
+type Handler struct {
+	logger *zaplogi.Logger
+}
+
+// NewHandler returns a Handler serving the sinks of l.
+func NewHandler(l *zaplogi.Logger) *Handler { return &Handler{logger: l} }
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, h.sinkLevels(h.logger.Sinks()))
+	case http.MethodPut:
+		var req UpdateRequest
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+		updated, err := h.update(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if len(updated) == 0 {
+			writeError(w, http.StatusNotFound, fmt.Errorf("no sink found; name=%q, logger_name=%q", req.Name, req.LoggerName))
+			return
+		}
+		writeJSON(w, http.StatusOK, h.sinkLevels(updated))
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// update changes the level range of every sink matching req and returns them.
+func (h *Handler) update(req UpdateRequest) ([]zaplogi.Sink, error) {
+	if req.Name == "" && req.LoggerName == "" {
+		return nil, fmt.Errorf("either name or logger_name must be specified")
+	}
+	// The range is checked before any sink is changed, so that sinks are
+	// never left partially updated.
+	if !req.LogRange[1].AtLeast(req.LogRange[0]) {
+		return nil, fmt.Errorf("log level high (%s) is smaller than low (%s)", req.LogRange[1], req.LogRange[0])
+	}
+	var updated []zaplogi.Sink
+	for _, s := range h.logger.Sinks() {
+		if req.Name != "" && s.Name != req.Name {
+			continue
+		}
+		if req.LoggerName != "" && !hasLoggerName(s, req.LoggerName) {
+			continue
+		}
+		if err := s.Level.SetLogRange(req.LogRange); err != nil {
+			return nil, err
+		}
+		updated = append(updated, s)
+	}
+	return updated, nil
+}
+
+// hasLoggerName returns true if name is the logger name or one of the logger
+// name patterns of s.
+func hasLoggerName(s zaplogi.Sink, name string) bool {
+	if s.LoggerName == name {
+		return true
+	}
+	for _, n := range s.LoggerNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) sinkLevels(sinks []zaplogi.Sink) SinkLevels {
+	res := SinkLevels{Sinks: make([]SinkLevel, 0, len(sinks))}
+	for _, s := range sinks {
+		res.Sinks = append(res.Sinks, SinkLevel{
+			Name:        s.Name,
+			LoggerName:  s.LoggerName,
+			LoggerNames: s.LoggerNames,
+			LogRange:    s.Level.LogRange(),
+		})
+	}
+	return res
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,225 @@
+package zaplogi
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DroppedKey is the key of the field counting the entries dropped by a rate
+// limit, see RateLimitConfig.
+const DroppedKey = "dropped"
+
+// defaultRateLimitReportInterval is the interval used when
+// RateLimitConfig.ReportInterval is not set.
+const defaultRateLimitReportInterval = 10 * time.Second
+
+// RateLimitConfig caps the entries written to a sink using token buckets
+// that allow bursts of up to a second's worth of entries and bytes. Entries
+// over either limit are dropped, and the number of dropped entries is
+// reported to the sink in a "dropped N entries" warn entry once per
+// ReportInterval while entries are being dropped, and when the logger is
+// synced or closed. Entries above the error level are never dropped.
+type RateLimitConfig struct {
+	// Entries is the maximum number of entries written per second. No limit
+	// if 0.
+	Entries int `json:"entries,omitempty" toml:"entries,omitempty" yaml:"entries,omitempty"`
+	// Bytes is the maximum number of encoded bytes written per second. An
+	// entry larger than Bytes is still written once the bucket is full. No
+	// limit if 0.
+	Bytes int `json:"bytes,omitempty" toml:"bytes,omitempty" yaml:"bytes,omitempty"`
+	// ReportInterval is the minimum interval between reports of dropped
+	// entries, e.g. "1m". Defaults to 10 seconds.
+	ReportInterval Duration `json:"report_interval,omitempty" toml:"report_interval,omitempty" yaml:"report-interval,omitempty"`
+}
+
+// newCore returns a core writing entries encoded by enc to ws, limited by the
+// config. A nil config does not limit the core. The returned closer, if not
+// nil, must be closed when the logger is closed.
+func (c *RateLimitConfig) newCore(enc zapcore.Encoder, ws zapcore.WriteSyncer, enab zapcore.LevelEnabler) (zapcore.Core, io.Closer) {
+	if c == nil || (c.Entries <= 0 && c.Bytes <= 0) {
+		return zapcore.NewCore(enc, ws, enab), nil
+	}
+	now := time.Now()
+	interval := time.Duration(c.ReportInterval)
+	if interval <= 0 {
+		interval = defaultRateLimitReportInterval
+	}
+	l := &rateLimiter{
+		entries:        newTokenBucket(c.Entries, now),
+		bytes:          newTokenBucket(c.Bytes, now),
+		reportInterval: interval,
+		lastReport:     now,
+		// The report is written straight to ws, so that it is never limited.
+		report: zapcore.NewCore(enc.Clone(), ws, zap.LevelEnablerFunc(func(zapcore.Level) bool { return true })),
+	}
+	return &rateLimitCore{LevelEnabler: enab, enc: enc, out: ws, limiter: l}, l
+}
+
+// tokenBucket is a token bucket refilled at rate tokens per second up to a
+// capacity of rate tokens. A nil tokenBucket allows everything.
+type tokenBucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate int, now time.Time) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	return &tokenBucket{rate: float64(rate), tokens: float64(rate), last: now}
+}
+
+// allows returns true if there are n tokens in the bucket, or if the bucket
+// is full when n is larger than its capacity.
+func (b *tokenBucket) allows(n float64, now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.rate, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+	return b.tokens >= math.Min(n, b.rate)
+}
+
+// take takes n tokens from the bucket if it allows them.
+func (b *tokenBucket) take(n float64, now time.Time) bool {
+	if !b.allows(n, now) {
+		return false
+	}
+	if b != nil {
+		b.tokens -= n
+	}
+	return true
+}
+
+// rateLimiter is the state of a rate limit, shared between a rateLimitCore
+// and all cores derived from it via With.
+type rateLimiter struct {
+	mu             sync.Mutex
+	entries        *tokenBucket
+	bytes          *tokenBucket
+	dropped        int
+	reportInterval time.Duration
+	lastReport     time.Time
+	// timer reports the dropped entries once the report interval has passed
+	// since the last report, and is nil if no entries have been dropped.
+	timer  *time.Timer
+	report zapcore.Core
+}
+
+// allow takes an entry and size bytes from the buckets if both allow them,
+// and otherwise counts the entry as dropped.
+func (l *rateLimiter) allow(size int, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.entries.allows(1, now) && l.bytes.allows(float64(size), now) {
+		l.entries.take(1, now)
+		l.bytes.take(float64(size), now)
+		return true
+	}
+	l.dropped++
+	if l.timer == nil {
+		var timer *time.Timer
+		timer = time.AfterFunc(l.lastReport.Add(l.reportInterval).Sub(now), func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.timer == timer {
+				// There is no caller to return the error to.
+				_ = l.reportDropped(time.Now())
+			}
+		})
+		l.timer = timer
+	}
+	return false
+}
+
+// reportDropped writes the number of dropped entries, if any, to the sink.
+// l.mu must be held.
+func (l *rateLimiter) reportDropped(now time.Time) error {
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	if l.dropped == 0 {
+		return nil
+	}
+	ent := zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    now,
+		Message: fmt.Sprintf("dropped %d entries", l.dropped),
+	}
+	fields := []zapcore.Field{zap.Int(DroppedKey, l.dropped)}
+	l.dropped = 0
+	l.lastReport = now
+	return l.report.Write(ent, fields)
+}
+
+// Close reports the dropped entries, if any, and stops the report timer.
+func (l *rateLimiter) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.reportDropped(time.Now())
+}
+
+// rateLimitCore writes entries to out like the core returned by
+// zapcore.NewCore, dropping those over the limits of its limiter. Entries
+// are encoded before they are limited, so that both limits are checked
+// before taking from either. Entries above the error level are never
+// dropped.
+type rateLimitCore struct {
+	zapcore.LevelEnabler
+	enc     zapcore.Encoder
+	out     zapcore.WriteSyncer
+	limiter *rateLimiter
+}
+
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &rateLimitCore{LevelEnabler: c.LevelEnabler, enc: c.enc.Clone(), out: c.out, limiter: c.limiter}
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+	return clone
+}
+
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *rateLimitCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+	if ErrorLevel.AtLeast(Level(ent.Level)) && !c.limiter.allow(buf.Len(), time.Now()) {
+		return nil
+	}
+	if _, err := c.out.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if !ErrorLevel.AtLeast(Level(ent.Level)) {
+		// Sync entries that may end the process, like zap's own cores.
+		return c.out.Sync()
+	}
+	return nil
+}
+
+func (c *rateLimitCore) Sync() error {
+	l := c.limiter
+	l.mu.Lock()
+	err := l.reportDropped(time.Now())
+	l.mu.Unlock()
+	return multierr.Append(err, c.out.Sync())
+}
@@ -0,0 +1,107 @@
+package zaplogi
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTokenBucketTake(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(10, now)
+	for i := 0; i < 10; i++ {
+		if !b.take(1, now) {
+			t.Fatalf("take %d: got false, want true within the burst", i)
+		}
+	}
+	if b.take(1, now) {
+		t.Fatal("take: got true, want false once the burst is used up")
+	}
+	if !b.take(1, now.Add(100*time.Millisecond)) {
+		t.Fatal("take: got false, want true after refilling a token")
+	}
+	if b.take(1, now.Add(100*time.Millisecond)) {
+		t.Fatal("take: got true, want false after taking the refilled token")
+	}
+	// A full bucket allows taking more than its capacity, going into debt.
+	if !b.take(25, now.Add(2*time.Second)) {
+		t.Fatal("take: got false, want true for a full bucket")
+	}
+	if b.take(1, now.Add(3*time.Second)) {
+		t.Fatal("take: got true, want false while in debt")
+	}
+	var unlimited *tokenBucket
+	if !unlimited.take(1e9, now) {
+		t.Fatal("take: got false, want true for a nil bucket")
+	}
+}
+
+// lockedBuffer is a bytes.Buffer that is safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Sync() error { return nil }
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestRateLimitCore(t *testing.T, c RateLimitConfig, ws zapcore.WriteSyncer) (*rateLimitCore, *rateLimiter) {
+	t.Helper()
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	core, closer := c.newCore(enc, ws, zapcore.DebugLevel)
+	t.Cleanup(func() { closer.Close() })
+	return core.(*rateLimitCore), closer.(*rateLimiter)
+}
+
+func TestRateLimitReportsWithoutWrites(t *testing.T) {
+	var buf lockedBuffer
+	core, _ := newTestRateLimitCore(t, RateLimitConfig{Entries: 1, ReportInterval: Duration(10 * time.Millisecond)}, &buf)
+	for i := 0; i < 3; i++ {
+		if err := core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "msg"}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(buf.String(), "dropped 2 entries") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 2 || !strings.Contains(buf.String(), "dropped 2 entries") {
+		t.Errorf("logged %q, want an entry and a report of 2 dropped entries", buf.String())
+	}
+}
+
+func TestRateLimitChecksBothLimits(t *testing.T) {
+	var buf lockedBuffer
+	core, l := newTestRateLimitCore(t, RateLimitConfig{Entries: 10, Bytes: 100, ReportInterval: Duration(time.Hour)}, &buf)
+	field := zap.String("padding", strings.Repeat("x", 40))
+	for i := 0; i < 2; i++ {
+		if err := core.Write(zapcore.Entry{Level: zapcore.InfoLevel, Message: "msg"}, []zapcore.Field{field}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.dropped != 1 {
+		t.Errorf("dropped %d entries, want 1 over the byte limit", l.dropped)
+	}
+	// The entry dropped over the byte limit must not take an entry token.
+	if l.entries.tokens < 8.5 {
+		t.Errorf("%v entry tokens left, want 9", l.entries.tokens)
+	}
+}
@@ -0,0 +1,214 @@
+package zaplogi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultRedactionReplacement is the replacement used when
+// RedactionConfig.Replacement is not set.
+const DefaultRedactionReplacement = "[REDACTED]"
+
+// Patterns of sensitive values that may be used in RedactionConfig.Values,
+// or by name in RedactionConfig.Patterns.
+const (
+	// JWTPattern matches JSON web tokens.
+	JWTPattern = `eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`
+	// EmailPattern matches email addresses.
+	EmailPattern = `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`
+	// PANPattern matches payment card numbers of 13 to 19 digits, which may
+	// be separated by spaces or dashes.
+	PANPattern = `\b\d(?:[ -]?\d){12,18}\b`
+)
+
+// redactionPatterns are the patterns that may be used by name in
+// RedactionConfig.Patterns.
+var redactionPatterns = map[string]string{
+	"jwt":   JWTPattern,
+	"email": EmailPattern,
+	"pan":   PANPattern,
+}
+
+// Redactor is implemented by values that redact themselves when logged, such
+// as structs with secret fields. Redact returns the value to log instead.
+// Redactor is honoured for values in key-value pairs and zap fields logged
+// via a Logger, whether or not LogConfig.Redaction is set.
+type Redactor interface {
+	Redact() interface{}
+}
+
+// RedactionConfig configures the redaction of values logged in key-value
+// pairs, including those added via With. Messages are not redacted, nor are
+// values nested within other values; implement Redactor for those instead.
+type RedactionConfig struct {
+	// Keys are the keys whose values are replaced, matched case-insensitively.
+	// Keys may be globs, where "*" matches any sequence of characters and "?"
+	// matches any single character, e.g. "*password*".
+	Keys []string `json:"keys,omitempty" toml:"keys,omitempty" yaml:"keys,omitempty"`
+	// Values are regular expressions, the matches of which are replaced
+	// within string values.
+	Values []string `json:"values,omitempty" toml:"values,omitempty" yaml:"values,omitempty"`
+	// Patterns are the names of predefined regular expressions used like
+	// Values: "jwt", "email" and "pan" (payment card numbers).
+	Patterns []string `json:"patterns,omitempty" toml:"patterns,omitempty" yaml:"patterns,omitempty"`
+	// Replacement replaces redacted values. Defaults to "[REDACTED]".
+	Replacement string `json:"replacement,omitempty" toml:"replacement,omitempty" yaml:"replacement,omitempty"`
+}
+
+// redactor redacts key-value pairs according to a RedactionConfig. A nil
+// redactor only honours Redactor.
+type redactor struct {
+	keys        *regexp.Regexp
+	values      []*regexp.Regexp
+	replacement string
+	// keyCache caches whether keys are redacted, as the same keys are logged
+	// over and over again.
+	keyCache sync.Map
+}
+
+// newRedactor returns the redactor of c. A nil config returns a nil redactor.
+func newRedactor(c *RedactionConfig) (*redactor, error) {
+	if c == nil {
+		return nil, nil
+	}
+	r := &redactor{replacement: c.Replacement}
+	if r.replacement == "" {
+		r.replacement = DefaultRedactionReplacement
+	}
+	if len(c.Keys) > 0 {
+		exprs := make([]string, len(c.Keys))
+		for i, k := range c.Keys {
+			exprs[i] = globExpr(k)
+		}
+		r.keys = regexp.MustCompile(`(?i)^(?:` + strings.Join(exprs, "|") + `)$`)
+	}
+	for _, name := range c.Patterns {
+		expr, ok := redactionPatterns[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unrecognised redaction pattern: %q", name)
+		}
+		r.values = append(r.values, regexp.MustCompile(expr))
+	}
+	for _, expr := range c.Values {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction value pattern %q: %w", expr, err)
+		}
+		r.values = append(r.values, re)
+	}
+	return r, nil
+}
+
+// wrap wraps core, which must write to a single sink, so that the fields
+// written to it are redacted. Fields are only redacted once an entry has
+// passed the checks of all the cores wrapping core, and is about to be
+// written.
+func (r *redactor) wrap(core zapcore.Core) zapcore.Core {
+	return &redactCore{Core: core, redactor: r}
+}
+
+// redactFields returns fields with their values redacted. fields is only
+// copied if any value is redacted.
+func (r *redactor) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := fields
+	copied := false
+	for i, f := range fields {
+		rf, changed := r.redactField(f)
+		if !changed {
+			continue
+		}
+		if !copied {
+			redacted = append([]zapcore.Field(nil), fields...)
+			copied = true
+		}
+		redacted[i] = rf
+	}
+	return redacted
+}
+
+// redactValue returns the redacted value of key, and whether it was changed.
+func (r *redactor) redactValue(key string, v interface{}) (interface{}, bool) {
+	if r.redactsKey(key) {
+		return r.replacement, true
+	}
+	changed := false
+	if rd, ok := v.(Redactor); ok {
+		v, changed = rd.Redact(), true
+	}
+	if s, ok := v.(string); ok {
+		if rs, ok := r.redactString(s); ok {
+			return rs, true
+		}
+	}
+	return v, changed
+}
+
+// redactField returns the redacted field, and whether it was changed.
+func (r *redactor) redactField(f zapcore.Field) (zapcore.Field, bool) {
+	if r.redactsKey(f.Key) {
+		return zap.String(f.Key, r.replacement), true
+	}
+	if f.Type == zapcore.StringType {
+		if s, ok := r.redactString(f.String); ok {
+			return zap.String(f.Key, s), true
+		}
+		return f, false
+	}
+	if rd, ok := f.Interface.(Redactor); ok {
+		v, _ := r.redactValue(f.Key, rd)
+		return zap.Any(f.Key, v), true
+	}
+	return f, false
+}
+
+func (r *redactor) redactsKey(key string) bool {
+	if r == nil || r.keys == nil {
+		return false
+	}
+	if redacts, ok := r.keyCache.Load(key); ok {
+		return redacts.(bool)
+	}
+	redacts := r.keys.MatchString(key)
+	r.keyCache.Store(key, redacts)
+	return redacts
+}
+
+// redactString returns s with the matches of the value patterns replaced,
+// and whether any were replaced.
+func (r *redactor) redactString(s string) (string, bool) {
+	if r == nil {
+		return s, false
+	}
+	redacted := s
+	for _, re := range r.values {
+		redacted = re.ReplaceAllLiteralString(redacted, r.replacement)
+	}
+	return redacted, redacted != s
+}
+
+// redactCore redacts the fields written to the core it wraps, including
+// those added via With.
+type redactCore struct {
+	zapcore.Core
+	redactor *redactor
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactor.redactFields(fields)), redactor: c.redactor}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.redactor.redactFields(fields))
+}
@@ -0,0 +1,255 @@
+package zaplogi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// isDescendant returns true if name is ancestor itself, or a descendant of
+// ancestor in the logger name hierarchy, e.g. "db.pool" is a descendant of
+// "db" but "dbx" is not.
+func isDescendant(name, ancestor string) bool {
+	return name == ancestor || strings.HasPrefix(name, ancestor) && name[len(ancestor)] == '.'
+}
+
+// nameMatcher matches logger names against a logger name pattern. A pattern
+// is one of:
+//   - a logger name, e.g. "db", which matches the name and its descendants.
+//   - a glob, e.g. "http.*", where "*" matches any sequence of characters
+//     (including ".") and "?" matches any single character.
+//   - a regular expression enclosed in slashes, e.g. "/grpc\.(health|admin)/",
+//     which is anchored to match the whole name.
+type nameMatcher struct {
+	name string
+	re   *regexp.Regexp
+	// specificity decides which pattern claims a name when multiple patterns
+	// match it; the more specific pattern wins. Logger names are more specific
+	// the deeper they are in the hierarchy, and globs are as specific as the
+	// logger name of their leading literal segments, plus one so that "db.*"
+	// wins over "db". Regular expressions are the least specific.
+	specificity int
+}
+
+func newNameMatcher(pattern string) (nameMatcher, error) {
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(`^(?:` + pattern[1:len(pattern)-1] + `)$`)
+		if err != nil {
+			return nameMatcher{}, fmt.Errorf("invalid logger name pattern %q: %w", pattern, err)
+		}
+		return nameMatcher{re: re, specificity: 1}, nil
+	}
+	wildcard := strings.IndexAny(pattern, "*?")
+	if wildcard < 0 {
+		return nameMatcher{name: pattern, specificity: 2 * (strings.Count(pattern, ".") + 1)}, nil
+	}
+	re, err := regexp.Compile("^" + globExpr(pattern) + "$")
+	if err != nil {
+		return nameMatcher{}, fmt.Errorf("invalid logger name pattern %q: %w", pattern, err)
+	}
+	literalSegments := strings.Count(pattern[:wildcard], ".")
+	return nameMatcher{re: re, specificity: 2*literalSegments + 1}, nil
+}
+
+// globExpr returns the regular expression of glob, where "*" matches any
+// sequence of characters and "?" matches any single character.
+func globExpr(glob string) string {
+	var expr strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return expr.String()
+}
+
+func (m nameMatcher) match(name string) bool {
+	if m.re != nil {
+		return m.re.MatchString(name)
+	}
+	return isDescendant(name, m.name)
+}
+
+func newNameMatchers(patterns []string) ([]nameMatcher, error) {
+	var matchers []nameMatcher
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+		m, err := newNameMatcher(p)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// sinkRoute holds the logger name patterns of a sink.
+type sinkRoute struct {
+	includes []nameMatcher
+	excludes []nameMatcher
+}
+
+func newSinkRoute(c LogFileConfig) (sinkRoute, error) {
+	patterns := c.LoggerNames
+	if c.LoggerName != "" {
+		patterns = append([]string{c.LoggerName}, patterns...)
+	}
+	includes, err := newNameMatchers(patterns)
+	if err != nil {
+		return sinkRoute{}, err
+	}
+	excludes, err := newNameMatchers(c.ExcludeLoggerNames)
+	if err != nil {
+		return sinkRoute{}, err
+	}
+	return sinkRoute{includes: includes, excludes: excludes}, nil
+}
+
+func (s sinkRoute) excluded(name string) bool {
+	for _, m := range s.excludes {
+		if m.match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// specificity returns the specificity of the most specific pattern of the
+// sink that matches name, or 0 if there is none.
+func (s sinkRoute) specificity(name string) int {
+	var specificity int
+	for _, m := range s.includes {
+		if m.specificity > specificity && m.match(name) {
+			specificity = m.specificity
+		}
+	}
+	return specificity
+}
+
+// route is the routing decision for a logger name.
+type route struct {
+	// sinks are the indices of the sinks that entries are logged to. These
+	// are the sinks with the most specific pattern matching the name; if no
+	// pattern matches, they are the sinks without any patterns instead.
+	// Sinks that exclude the name are never included.
+	sinks map[int]bool
+	// minLevel is the minimum level for the logger name, taken from the most
+	// specific LogConfig.LoggerLevels entry. hasMinLevel is false if there is
+	// no such entry.
+	minLevel    Level
+	hasMinLevel bool
+}
+
+// maxCachedRoutes is the maximum number of logger names whose routes are
+// cached by a router.
+const maxCachedRoutes = 4096
+
+// router routes entries to sinks based on their logger names. Routing
+// decisions are cached per logger name so that checking entries stays cheap.
+// Only the first maxCachedRoutes names are cached, so that loggers named
+// e.g. per request do not grow the cache without bound; the routes of other
+// names are worked out for every entry.
+type router struct {
+	sinkRoutes   []sinkRoute
+	loggerLevels map[string]Level
+	cache        sync.Map // map[string]*route
+	cached       atomic.Int64
+}
+
+func newRouter(sinkRoutes []sinkRoute, loggerLevels map[string]Level) *router {
+	return &router{sinkRoutes: sinkRoutes, loggerLevels: loggerLevels}
+}
+
+func (r *router) route(name string) *route {
+	if rt, ok := r.cache.Load(name); ok {
+		return rt.(*route)
+	}
+	rt := &route{sinks: make(map[int]bool)}
+	var maxSpecificity int
+	for i, s := range r.sinkRoutes {
+		if s.excluded(name) {
+			continue
+		}
+		specificity := s.specificity(name)
+		if specificity > maxSpecificity {
+			maxSpecificity = specificity
+			rt.sinks = map[int]bool{}
+		}
+		if specificity == maxSpecificity {
+			rt.sinks[i] = true
+		}
+	}
+	if maxSpecificity == 0 {
+		// no pattern matched; only keep the sinks without any patterns.
+		for i := range rt.sinks {
+			if len(r.sinkRoutes[i].includes) > 0 {
+				delete(rt.sinks, i)
+			}
+		}
+	}
+	var levelName string
+	for n, lvl := range r.loggerLevels {
+		// The empty name is the root logger, which applies to all names.
+		if (n == "" || isDescendant(name, n)) && (!rt.hasMinLevel || len(n) > len(levelName)) {
+			levelName, rt.minLevel, rt.hasMinLevel = n, lvl, true
+		}
+	}
+	if r.cached.Load() >= maxCachedRoutes {
+		return rt
+	}
+	actual, loaded := r.cache.LoadOrStore(name, rt)
+	if !loaded {
+		r.cached.Add(1)
+	}
+	return actual.(*route)
+}
+
+// consoleSink is the sink index used for the console, which accepts entries of
+// all logger names, subject only to the logger levels.
+const consoleSink = -1
+
+// accepts returns true if an entry should be logged to the sink at index sink.
+func (r *router) accepts(ent zapcore.Entry, sink int) bool {
+	rt := r.route(ent.LoggerName)
+	if rt.hasMinLevel && !Level(ent.Level).AtLeast(rt.minLevel) {
+		return false
+	}
+	return sink == consoleSink || rt.sinks[sink]
+}
+
+// routedCore is a wrapper around zapcore.Core that only checks entries that
+// the router routes to the sink.
+type routedCore struct {
+	router *router
+	sink   int
+	zapcore.Core
+}
+
+func newRoutedCore(r *router, sink int, core zapcore.Core) zapcore.Core {
+	return &routedCore{router: r, sink: sink, Core: core}
+}
+
+func (c *routedCore) With(fields []zapcore.Field) zapcore.Core {
+	return newRoutedCore(c.router, c.sink, c.Core.With(fields))
+}
+
+// Check overrides the underlying zapcore.Core implementation by having a check on whether to include
+// based on the entry's logger name.
+// nolint // to satisfy zapcore.Core interface
+func (c *routedCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.router.accepts(ent, c.sink) {
+		return c.Core.Check(ent, ce)
+	}
+	return ce
+}
@@ -0,0 +1,26 @@
+package zaplogi
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRouterCacheBounded(t *testing.T) {
+	sr, err := newSinkRoute(LogFileConfig{LoggerNames: []string{"tenant.*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRouter([]sinkRoute{sr, {}}, nil)
+	for i := 0; i < maxCachedRoutes+10; i++ {
+		name := fmt.Sprintf("tenant.%d", i)
+		if rt := r.route(name); !rt.sinks[0] || rt.sinks[1] {
+			t.Fatalf("route(%q) = %v, want only the tenant sink", name, rt.sinks)
+		}
+	}
+	if got := r.cached.Load(); got != maxCachedRoutes {
+		t.Errorf("cached %d routes, want %d", got, maxCachedRoutes)
+	}
+	if _, ok := r.cache.Load(fmt.Sprintf("tenant.%d", maxCachedRoutes)); ok {
+		t.Error("route cached beyond maxCachedRoutes")
+	}
+}
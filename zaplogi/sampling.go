@@ -0,0 +1,236 @@
+package zaplogi
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RepeatedKey is the key of the field added to the entry that ends a run of
+// identical entries collapsed by SamplingConfig.Dedup.
+const RepeatedKey = "repeated"
+
+// defaultSamplingTick is the sampling interval used when
+// SamplingConfig.Tick is not set.
+const defaultSamplingTick = time.Second
+
+// SamplingConfig limits how many entries are logged to a sink.
+type SamplingConfig struct {
+	// Initial is the number of entries with the same level and message logged
+	// per Tick, after which only every Thereafter-th entry is logged.
+	// Sampling is disabled if both Initial and Thereafter are 0.
+	Initial int `json:"initial,omitempty" toml:"initial,omitempty" yaml:"initial,omitempty"`
+	// Thereafter is the interval of entries logged after the first Initial
+	// entries within a Tick. If 0, all entries after the first Initial are
+	// dropped until the next Tick.
+	Thereafter int `json:"thereafter,omitempty" toml:"thereafter,omitempty" yaml:"thereafter,omitempty"`
+	// Tick is the interval that entries are counted in, e.g. "1s".
+	// Defaults to 1 second.
+	Tick Duration `json:"tick,omitempty" toml:"tick,omitempty" yaml:"tick,omitempty"`
+	// Dedup collapses consecutive identical entries, i.e. with the same
+	// level, logger name, message, caller and fields. The first entry is
+	// logged as is, and when a different entry is logged, the logger is
+	// synced or a Tick has passed since the first repeat, the last of the
+	// repeated entries is logged again with a RepeatedKey field counting the
+	// entries that were dropped.
+	// Entries above the error level are never collapsed.
+	Dedup bool `json:"dedup,omitempty" toml:"dedup,omitempty" yaml:"dedup,omitempty"`
+}
+
+// wrap wraps core, which must write to a single sink, with the sampling and
+// deduplication of the config. A nil config returns core as is. The returned
+// closer, if not nil, must be closed when the logger is closed.
+func (c *SamplingConfig) wrap(core zapcore.Core) (zapcore.Core, io.Closer) {
+	if c == nil {
+		return core, nil
+	}
+	tick := time.Duration(c.Tick)
+	if tick <= 0 {
+		tick = defaultSamplingTick
+	}
+	var closer io.Closer
+	if c.Dedup {
+		state := &dedupState{flushAfter: tick}
+		core = &dedupCore{Core: core, state: state}
+		closer = state
+	}
+	if c.Initial > 0 || c.Thereafter > 0 {
+		core = zapcore.NewSamplerWithOptions(core, tick, c.Initial, c.Thereafter)
+	}
+	return core, closer
+}
+
+// dedupState is the last entry written to a sink, shared between a dedupCore
+// and all cores derived from it via With.
+type dedupState struct {
+	mu sync.Mutex
+	// core is the core that wrote the last entry, which is used to write the
+	// entry again when the run of repeated entries ends.
+	core     *dedupCore
+	ent      zapcore.Entry
+	fields   []zapcore.Field
+	repeated int
+	// flushAfter is how long after the first repeat the run is flushed, so
+	// that a trailing run is reported even if nothing else is logged.
+	flushAfter time.Duration
+	timer      *time.Timer
+	// run counts the flushes, so that a timer of an earlier run that fires
+	// late does not flush the current run.
+	run int
+}
+
+// dedupCore collapses consecutive identical entries written to the core it
+// wraps. It must wrap the core of a single sink.
+type dedupCore struct {
+	zapcore.Core
+	// context are the fields added via With, which are compared as well.
+	context []zapcore.Field
+	state   *dedupState
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{
+		Core:    c.Core.With(fields),
+		context: append(c.context[:len(c.context):len(c.context)], fields...),
+		state:   c.state,
+	}
+}
+
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *dedupCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ErrorLevel.AtLeast(Level(ent.Level)) && s.core != nil && s.core.isRepeat(c, ent, fields, s) {
+		s.repeated++
+		s.ent = ent
+		if s.repeated == 1 {
+			run := s.run
+			s.timer = time.AfterFunc(s.flushAfter, func() { s.flushRun(run) })
+		}
+		return nil
+	}
+	err := s.flush()
+	s.core, s.ent, s.fields = c, ent, fields
+	return multierr.Append(err, c.Core.Write(ent, fields))
+}
+
+func (c *dedupCore) Sync() error {
+	s := c.state
+	s.mu.Lock()
+	err := s.flush()
+	s.mu.Unlock()
+	return multierr.Append(err, c.Core.Sync())
+}
+
+// isRepeat returns true if the entry written to other is identical to the
+// last entry s, which was written to c.
+func (c *dedupCore) isRepeat(other *dedupCore, ent zapcore.Entry, fields []zapcore.Field, s *dedupState) bool {
+	return ent.Level == s.ent.Level &&
+		ent.LoggerName == s.ent.LoggerName &&
+		ent.Message == s.ent.Message &&
+		sameCaller(ent.Caller, s.ent.Caller) &&
+		(c == other || fieldsEqual(c.context, other.context)) &&
+		fieldsEqual(fields, s.fields)
+}
+
+// sameCaller returns true if a and b are the same call site. Program counters
+// are not compared, as they may differ for the same line, e.g. when inlined.
+func sameCaller(a, b zapcore.EntryCaller) bool {
+	return a.Defined == b.Defined && a.File == b.File && a.Line == b.Line && a.Function == b.Function
+}
+
+// flush writes the last entry again with the number of times it was repeated
+// if any, and resets the repeat count. s.mu must be held.
+func (s *dedupState) flush() error {
+	if s.repeated == 0 {
+		return nil
+	}
+	s.timer.Stop()
+	s.timer = nil
+	s.run++
+	fields := append(s.fields[:len(s.fields):len(s.fields)], zap.Int(RepeatedKey, s.repeated))
+	s.repeated = 0
+	return s.core.Core.Write(s.ent, fields)
+}
+
+// flushRun flushes the current run when its timer fires, unless it has
+// already been flushed.
+func (s *dedupState) flushRun(run int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if run == s.run {
+		// There is no caller to return the error to.
+		_ = s.flush()
+	}
+}
+
+// Close flushes the current run, if any, which also stops its timer.
+func (s *dedupState) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush()
+}
+
+// fieldsEqual returns true if a and b are equal. Fields that cannot be
+// compared are treated as not equal.
+func fieldsEqual(a, b []zapcore.Field) (equal bool) {
+	if len(a) != len(b) {
+		return false
+	}
+	defer func() {
+		if recover() != nil {
+			// Field.Equals panics on uncomparable values, e.g. a slice based
+			// fmt.Stringer.
+			equal = false
+		}
+	}()
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Duration is a time.Duration that is marshalled to text as e.g. "1m30s".
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+// UnmarshalText unmarshals text such as "1m30s" to a duration, see
+// time.ParseDuration.
+// In particular, this makes it easy to configure durations using YAML,
+// TOML, or JSON files.
+func (d *Duration) UnmarshalText(text []byte) error {
+	if d == nil {
+		return errors.New("can't unmarshal a nil *Duration")
+	}
+	if len(text) == 0 {
+		*d = 0
+		return nil
+	}
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("unrecognised Duration: %q", text)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// String returns the duration formatted like time.Duration.String.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
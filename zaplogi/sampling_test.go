@@ -0,0 +1,113 @@
+package zaplogi
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newTestDedupCore returns a dedupCore writing entries at or above lvl to
+// an observer.
+func newTestDedupCore(lvl zapcore.Level, flushAfter time.Duration) (*dedupCore, *observer.ObservedLogs) {
+	core, logs := observer.New(lvl)
+	return &dedupCore{Core: core, state: &dedupState{flushAfter: flushAfter}}, logs
+}
+
+// writeEntry writes an entry with msg at lvl to c, if c is enabled for it.
+func writeEntry(t *testing.T, c zapcore.Core, lvl zapcore.Level, msg string, fields ...zapcore.Field) {
+	t.Helper()
+	if ce := c.Check(zapcore.Entry{Level: lvl, Message: msg}, nil); ce != nil {
+		ce.Write(fields...)
+	}
+}
+
+// repeats returns the messages of logs, and the RepeatedKey field of each
+// entry, 0 if it has none.
+func repeats(logs *observer.ObservedLogs) ([]string, []int64) {
+	var msgs []string
+	var counts []int64
+	for _, e := range logs.AllUntimed() {
+		msgs = append(msgs, e.Message)
+		count, _ := e.ContextMap()[RepeatedKey].(int64)
+		counts = append(counts, count)
+	}
+	return msgs, counts
+}
+
+func assertRepeats(t *testing.T, logs *observer.ObservedLogs, wantMsgs []string, wantCounts []int64) {
+	t.Helper()
+	msgs, counts := repeats(logs)
+	if len(msgs) != len(wantMsgs) {
+		t.Fatalf("logged %q with repeats %v, want %q with repeats %v", msgs, counts, wantMsgs, wantCounts)
+	}
+	for i := range msgs {
+		if msgs[i] != wantMsgs[i] || counts[i] != wantCounts[i] {
+			t.Fatalf("logged %q with repeats %v, want %q with repeats %v", msgs, counts, wantMsgs, wantCounts)
+		}
+	}
+}
+
+func TestDedupCoreInterleavedSinks(t *testing.T) {
+	all, allLogs := newTestDedupCore(zapcore.DebugLevel, time.Hour)
+	warn, warnLogs := newTestDedupCore(zapcore.WarnLevel, time.Hour)
+	core := zapcore.NewTee(all, warn)
+	for i := 0; i < 3; i++ {
+		writeEntry(t, core, zapcore.WarnLevel, "warn")
+		writeEntry(t, core, zapcore.InfoLevel, "info")
+	}
+	if err := core.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	// The info entries break the runs of the sink logging them only.
+	assertRepeats(t, allLogs, []string{"warn", "info", "warn", "info", "warn", "info"}, []int64{0, 0, 0, 0, 0, 0})
+	assertRepeats(t, warnLogs, []string{"warn", "warn"}, []int64{0, 2})
+}
+
+func TestDedupCoreWithFields(t *testing.T) {
+	c, logs := newTestDedupCore(zapcore.DebugLevel, time.Hour)
+	one := c.With([]zapcore.Field{zap.Int("k", 1)})
+	writeEntry(t, one, zapcore.InfoLevel, "msg")
+	writeEntry(t, c.With([]zapcore.Field{zap.Int("k", 1)}), zapcore.InfoLevel, "msg")
+	writeEntry(t, c.With([]zapcore.Field{zap.Int("k", 2)}), zapcore.InfoLevel, "msg")
+	writeEntry(t, one, zapcore.InfoLevel, "msg", zap.String("extra", "field"))
+	writeEntry(t, one, zapcore.InfoLevel, "msg")
+	if err := c.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	// Equal With fields on different cores continue a run, different ones
+	// break it.
+	assertRepeats(t, logs, []string{"msg", "msg", "msg", "msg", "msg"}, []int64{0, 1, 0, 0, 0})
+}
+
+func TestDedupCoreLevels(t *testing.T) {
+	c, logs := newTestDedupCore(zapcore.DebugLevel, time.Hour)
+	for _, lvl := range []zapcore.Level{zapcore.ErrorLevel, zapcore.DPanicLevel} {
+		for i := 0; i < 3; i++ {
+			writeEntry(t, c, lvl, lvl.String())
+		}
+	}
+	if err := c.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	// Entries above the error level are never collapsed.
+	assertRepeats(t, logs, []string{"error", "error", "dpanic", "dpanic", "dpanic"}, []int64{0, 2, 0, 0, 0})
+}
+
+func TestDedupCoreFlushesAfterTimeout(t *testing.T) {
+	c, logs := newTestDedupCore(zapcore.DebugLevel, 10*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		writeEntry(t, c, zapcore.InfoLevel, "msg")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for logs.Len() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assertRepeats(t, logs, []string{"msg", "msg"}, []int64{0, 2})
+	if err := c.state.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertRepeats(t, logs, []string{"msg", "msg"}, []int64{0, 2})
+}
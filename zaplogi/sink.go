@@ -0,0 +1,78 @@
+package zaplogi
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ConsoleSinkName is the name of the sink that logs to stdout and stderr.
+const ConsoleSinkName = "console"
+
+// fileSinkName returns the name of the sink built from the i-th LogFileConfig.
+func fileSinkName(i int) string { return fmt.Sprintf("file[%d]", i) }
+
+// Sink is a handle to one of the outputs that a Logger writes to. The level
+// range of a sink may be changed at runtime via its Level, without having to
+// rebuild the Logger.
+type Sink struct {
+	// Name identifies the sink within the Logger. The console sink is named
+	// "console" while file sinks are named "file[i]", where i is the index of
+	// the sink's LogFileConfig in LogConfig.LogFileConfigs.
+	Name string
+	// LoggerName is the LoggerName of the sink's LogFileConfig, if any.
+	LoggerName string
+	// LoggerNames are the LoggerNames patterns of the sink's LogFileConfig,
+	// if any.
+	LoggerNames []string
+	// Level is the level range that the sink currently logs under.
+	Level *AtomicLevelRange
+	// route identifies the sink to the router, see routedCore.
+	route int
+	// Async is the writer that queues the sink's writes if its LogFileConfig
+	// is asynchronous, which reports the sink's queue metrics via Stats.
+	Async *AsyncWriter
+}
+
+// AtomicLevelRange is a level range that is safe to read and change
+// concurrently. It implements zapcore.LevelEnabler, enabling levels that fall
+// within the range (inclusive).
+type AtomicLevelRange struct {
+	v atomic.Uint32
+}
+
+// NewAtomicLevelRange returns an AtomicLevelRange set to logRange.
+func NewAtomicLevelRange(logRange [2]Level) (*AtomicLevelRange, error) {
+	r := &AtomicLevelRange{}
+	if err := r.SetLogRange(logRange); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LogRange returns the current level range.
+func (r *AtomicLevelRange) LogRange() [2]Level {
+	v := r.v.Load()
+	return [2]Level{Level(int8(v >> 8)), Level(int8(v))}
+}
+
+// SetLogRange changes the level range. It returns an error if the low level of
+// logRange is higher than its high level.
+func (r *AtomicLevelRange) SetLogRange(logRange [2]Level) error {
+	low, high := logRange[0], logRange[1]
+	if !high.AtLeast(low) {
+		return fmt.Errorf("log level high (%s) is smaller than low (%s)", high.String(), low.String())
+	}
+	r.v.Store(uint32(uint8(low))<<8 | uint32(uint8(high)))
+	return nil
+}
+
+// Enabled returns true if lvl is within the level range.
+func (r *AtomicLevelRange) Enabled(lvl zapcore.Level) bool {
+	logRange := r.LogRange()
+	return Level(lvl).AtLeast(logRange[0]) && logRange[1].AtLeast(Level(lvl))
+}
+
+// String returns the level range in the same format as LogFileConfig.LogRange.
+func (r *AtomicLevelRange) String() string { return fmt.Sprint(r.LogRange()) }
@@ -0,0 +1,129 @@
+package zaplogi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// File is a log file that is not rotated, used for the "file" LogFileType.
+// The file is opened when it is first written to.
+type File struct {
+	// Filename is the file to write to.
+	Filename string `json:"filename" yaml:"filename"`
+	// Perm is the permission bits used if the file has to be created.
+	// Defaults to 0644.
+	Perm FileMode `json:"perm" yaml:"perm"`
+	// Truncate truncates the file when it is first opened, instead of
+	// appending to it. The file is never truncated when reopened after Close.
+	Truncate bool `json:"truncate" yaml:"truncate"`
+	// MkdirParents creates the parent directories of the file if they do not
+	// exist, with permission bits 0755.
+	MkdirParents bool `json:"mkdir_parents" yaml:"mkdir-parents"`
+
+	mu   sync.Mutex
+	file *os.File
+	// opened is set once the file has been opened, after which it is no
+	// longer truncated.
+	opened bool
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	return f.file.Write(p)
+}
+
+// open opens the file. Callers must hold f.mu.
+func (f *File) open() error {
+	if f.Filename == "" {
+		return errors.New("file: filename must not be empty")
+	}
+	if f.MkdirParents {
+		if err := os.MkdirAll(filepath.Dir(f.Filename), 0o755); err != nil {
+			return err
+		}
+	}
+	flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if f.Truncate && !f.opened {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	perm := os.FileMode(f.Perm)
+	if perm == 0 {
+		perm = 0o644
+	}
+	file, err := os.OpenFile(f.Filename, flag, perm)
+	if err != nil {
+		return err
+	}
+	f.file = file
+	f.opened = true
+	return nil
+}
+
+// Sync commits the contents of the file to stable storage.
+func (f *File) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Close closes the file. The file is reopened for appending if it is written
+// to again.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// FileMode is an os.FileMode that is marshalled as an octal string, e.g.
+// "0644".
+type FileMode os.FileMode
+
+func (m FileMode) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%#o", uint32(m))), nil
+}
+
+// UnmarshalText unmarshals an octal string to a file mode.
+func (m *FileMode) UnmarshalText(text []byte) error {
+	if m == nil {
+		return errors.New("can't unmarshal a nil *FileMode")
+	}
+	if len(text) == 0 {
+		*m = 0
+		return nil
+	}
+	v, err := strconv.ParseUint(string(text), 8, 32)
+	if err != nil {
+		return fmt.Errorf("invalid file mode %q: %w", text, err)
+	}
+	*m = FileMode(v)
+	return nil
+}
+
+// stdoutWriter writes to os.Stdout, used for the "stdout" LogFileType.
+type stdoutWriter struct{}
+
+func (stdoutWriter) Write(p []byte) (int, error) { return os.Stdout.Write(p) }
+
+// stderrWriter writes to os.Stderr, used for the "stderr" LogFileType.
+type stderrWriter struct{}
+
+func (stderrWriter) Write(p []byte) (int, error) { return os.Stderr.Write(p) }
@@ -0,0 +1,34 @@
+package zaplogi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileTruncateOnlyOnFirstOpen(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(filename, []byte("previous run\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f := &File{Filename: filename, Truncate: true}
+	if _, err := f.Write([]byte("before close\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("after close\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "before close\nafter close\n"; string(content) != want {
+		t.Errorf("file content = %q, want %q", content, want)
+	}
+}
@@ -0,0 +1,161 @@
+package zaplogi
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/lohvht/logfeller"
+	"github.com/pkg/errors"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// NewWriterFunc returns a new, zero valued writer of a LogFileType. The
+// `file_handler` block of a LogFileConfig is decoded into the returned writer,
+// so it should be a pointer to a struct with the appropriate JSON and YAML
+// tags. The TOML schema is the same as the JSON schema.
+//
+// As the writer is created while the config is being decoded, it should defer
+// acquiring any resources, such as opening files, until it is first written
+// to. See lumberjack.Logger for an example.
+type NewWriterFunc func() io.Writer
+
+type LogFileType int
+
+const (
+	NoWriter LogFileType = iota
+	Lumberjack
+	Logfeller
+	// PlainFile writes to a File, which is not rotated.
+	PlainFile
+	// Stdout writes to os.Stdout. It takes no `file_handler`.
+	Stdout
+	// Stderr writes to os.Stderr. It takes no `file_handler`.
+	Stderr
+)
+
+// writerType is a registered LogFileType.
+type writerType struct {
+	// names that the type may be unmarshalled from, the first of which is the
+	// name that the type is marshalled to.
+	names     []string
+	newWriter NewWriterFunc
+	// goType is the type of the writers returned by newWriter, used to infer
+	// the LogFileType of a writer.
+	goType reflect.Type
+}
+
+var (
+	writerTypesMu sync.RWMutex
+	// writerTypes are indexed by their LogFileType.
+	writerTypes = []writerType{
+		NoWriter:   {names: []string{"noWriter", ""}},
+		Lumberjack: newWriterType(func() io.Writer { return &lumberjack.Logger{} }, "lumberjack", "lj"),
+		Logfeller:  newWriterType(func() io.Writer { return &logfeller.File{} }, "logfeller", "lf"),
+		PlainFile:  newWriterType(func() io.Writer { return &File{} }, "file", "plain"),
+		Stdout:     newWriterType(func() io.Writer { return &stdoutWriter{} }, "stdout"),
+		Stderr:     newWriterType(func() io.Writer { return &stderrWriter{} }, "stderr"),
+	}
+)
+
+func newWriterType(newWriter NewWriterFunc, name string, aliases ...string) writerType {
+	return writerType{
+		names:     append([]string{name}, aliases...),
+		newWriter: newWriter,
+		goType:    reflect.TypeOf(newWriter()),
+	}
+}
+
+// RegisterWriterType registers a new LogFileType under name and its aliases,
+// which allows LogFileConfig.Type to be set to any of them when unmarshalling.
+// Names are matched case insensitively and must not already be registered.
+// RegisterWriterType is typically called in an init function.
+func RegisterWriterType(newWriter NewWriterFunc, name string, aliases ...string) (LogFileType, error) {
+	if newWriter == nil {
+		return NoWriter, errors.New("newWriter must not be nil")
+	}
+	if name == "" {
+		return NoWriter, errors.New("name must not be empty")
+	}
+	wt := newWriterType(newWriter, name, aliases...)
+	writerTypesMu.Lock()
+	defer writerTypesMu.Unlock()
+	for _, n := range wt.names {
+		if t, ok := lookupWriterType(n); ok {
+			return NoWriter, fmt.Errorf("name %q is already registered to LogFileType %q", n, writerTypes[t].names[0])
+		}
+	}
+	writerTypes = append(writerTypes, wt)
+	return LogFileType(len(writerTypes) - 1), nil
+}
+
+// lookupWriterType returns the LogFileType registered under name. Callers must
+// hold writerTypesMu.
+func lookupWriterType(name string) (LogFileType, bool) {
+	for t, wt := range writerTypes {
+		for _, n := range wt.names {
+			if strings.EqualFold(n, name) {
+				return LogFileType(t), true
+			}
+		}
+	}
+	return NoWriter, false
+}
+
+// writerTypeOf returns the registered LogFileType of w, or NoWriter if there
+// is none.
+func writerTypeOf(w io.Writer) LogFileType {
+	if w == nil {
+		return NoWriter
+	}
+	goType := reflect.TypeOf(w)
+	writerTypesMu.RLock()
+	defer writerTypesMu.RUnlock()
+	for t, wt := range writerTypes {
+		if wt.goType == goType {
+			return LogFileType(t)
+		}
+	}
+	return NoWriter
+}
+
+// newWriterFunc returns the NewWriterFunc of the type, if it is registered.
+func (t LogFileType) newWriterFunc() (NewWriterFunc, bool) {
+	writerTypesMu.RLock()
+	defer writerTypesMu.RUnlock()
+	if t <= NoWriter || int(t) >= len(writerTypes) {
+		return nil, false
+	}
+	return writerTypes[t].newWriter, true
+}
+
+func (t LogFileType) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+// UnmarshalText unmarshals text to a log file type.
+// In particular, this makes it easy to configure log file types using YAML,
+// TOML, or JSON files.
+func (t *LogFileType) UnmarshalText(text []byte) error {
+	if t == nil {
+		return errors.New("can't unmarshal a nil *LogFileType")
+	}
+	writerTypesMu.RLock()
+	defer writerTypesMu.RUnlock()
+	found, ok := lookupWriterType(string(text))
+	if !ok {
+		return fmt.Errorf("unrecognised LogFileType: %q", text)
+	}
+	*t = found
+	return nil
+}
+
+// String returns the name that the log file type was registered under
+func (t LogFileType) String() string {
+	writerTypesMu.RLock()
+	defer writerTypesMu.RUnlock()
+	if t < NoWriter || int(t) >= len(writerTypes) {
+		return fmt.Sprintf("LogFileType(%d)", t)
+	}
+	return writerTypes[t].names[0]
+}
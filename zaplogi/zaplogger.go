@@ -3,14 +3,16 @@ package zaplogi
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"sort"
+	"reflect"
 	"strings"
-	"time"
 
 	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
@@ -20,26 +22,14 @@ import (
 
 type Logger struct {
 	zaplog *zap.SugaredLogger
-}
-
-// defaultEncoderConfig returns the default encoding used. Note that EncodeLevel
-// is encoded in colour by default.
-func defaultEncoderConfig() zapcore.EncoderConfig {
-	return zapcore.EncoderConfig{
-		MessageKey: "msg",
-		TimeKey:    "timestamp",
-		EncodeTime: func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
-			enc.AppendString(t.Format("2006-01-02 15:04:05.000Z0700"))
-		},
-		LevelKey:       "level",
-		EncodeLevel:    zapcore.CapitalColorLevelEncoder,
-		NameKey:        "logger",
-		CallerKey:      "caller",
-		EncodeCaller:   zapcore.ShortCallerEncoder,
-		StacktraceKey:  "stacktrace",
-		EncodeDuration: zapcore.SecondsDurationEncoder,
-		LineEnding:     zapcore.DefaultLineEnding,
-	}
+	// sinks are shared between the Logger and all loggers derived from it.
+	sinks []Sink
+	// closers are the writers closed by Close, shared like sinks.
+	closers []io.Closer
+	// router routes entries to sinks, which Enabled checks against.
+	router *router
+	// name is the logger's name, as zap does not expose it.
+	name string
 }
 
 // NewWithConfig returns a Logger with the given config. Logger
@@ -48,58 +38,90 @@ func defaultEncoderConfig() zapcore.EncoderConfig {
 // With this function, you can customise the log output and how you would want
 // to initialise the logger. However, usually New will suffice.
 func NewWithConfig(c LogConfig) (*Logger, error) {
-	encConf := defaultEncoderConfig()
-	var enc zapcore.Encoder
 	var childCores []zapcore.Core
+	var sinks []Sink
+	var closers []io.Closer
+	var asyncWriters []*AsyncWriter
 	options := []zap.Option{zap.AddCallerSkip(c.RootCallerSkip), zap.AddCaller()}
-	if c.ConsoleLog {
-		enc = zapcore.NewConsoleEncoder(encConf)
-		stdoutPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-			// log debugs to stdout
-			return lvl < zapcore.WarnLevel
-		})
-		stdErrPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-			return lvl >= zapcore.WarnLevel
-		})
-		stdoutCore := zapcore.NewCore(enc, zapcore.Lock(os.Stdout), stdoutPriority)
-		stderrCore := zapcore.NewCore(enc, zapcore.Lock(os.Stderr), stdErrPriority)
-		childCores = append(childCores, stdoutCore, stderrCore)
-		// add in zap.Development()
-		options = append(options, zap.Development())
-	}
-	var loggerNamesToExclude []string
-	// Collect all the logger names to exclude first
-	for _, logConf := range c.LogFileConfigs {
-		if logConf.LoggerName != "" {
-			loggerNamesToExclude = append(loggerNamesToExclude, logConf.LoggerName)
+	var Errs []error
+	// Collect the logger name patterns of all the sinks first
+	sinkRoutes := make([]sinkRoute, len(c.LogFileConfigs))
+	for i, logConf := range c.LogFileConfigs {
+		sr, err := newSinkRoute(logConf)
+		if err != nil {
+			Errs = append(Errs, err)
+			continue
 		}
+		sinkRoutes[i] = sr
 	}
-	var Errs []error
-	// change the encoding back
-	encConf.EncodeLevel = zapcore.CapitalLevelEncoder
-	enc = zapcore.NewConsoleEncoder(encConf)
-	for _, logConf := range c.LogFileConfigs {
-		low, high := logConf.LogRange[0], logConf.LogRange[1]
-		if low > high {
-			Errs = append(Errs, fmt.Errorf("log level high (%s) is smaller than low (%s)", high.String(), low.String()))
+	r := newRouter(sinkRoutes, c.LoggerLevels)
+	red, err := newRedactor(c.Redaction)
+	if err != nil {
+		Errs = append(Errs, err)
+	}
+	if cc, ok := c.consoleConfig(); ok {
+		consoleCores, consoleSink, consoleOptions, consoleClosers, err := newConsoleCores(cc, c.EncoderConfig, c.Sampling, red, r)
+		if err != nil {
+			return nil, err
+		}
+		childCores = append(childCores, consoleCores...)
+		closers = append(closers, consoleClosers...)
+		sinks = append(sinks, consoleSink)
+		options = append(options, consoleOptions...)
+	}
+	for i, logConf := range c.LogFileConfigs {
+		lvlRange, err := NewAtomicLevelRange(logConf.LogRange)
+		if err != nil {
+			Errs = append(Errs, err)
+			continue
+		}
+		encConf, err := c.EncoderConfig.merge(logConf.EncoderConfig).build(false)
+		if err != nil {
+			Errs = append(Errs, err)
+			continue
+		}
+		enc, err := newEncoder(logConf.Encoding, encConf)
+		if err != nil {
+			Errs = append(Errs, err)
 			continue
 		}
-		lvlFn := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-			// Dont log debugs at all
-			return lvl >= zapcore.Level(low) && lvl <= zapcore.Level(high)
-		})
 		if logConf.Writer != nil {
 			// Only allow logging if the writer is initialised.
-			var childCore zapcore.Core
-			if logConf.LoggerName != "" {
-				childCore = newExclusiveCore([]string{logConf.LoggerName}, true, zapcore.NewCore(enc, zapcore.AddSync(logConf), lvlFn))
-			} else {
-				childCore = newExclusiveCore(loggerNamesToExclude, false, zapcore.NewCore(enc, zapcore.AddSync(logConf), lvlFn))
+			sampling := c.Sampling
+			if logConf.Sampling != nil {
+				sampling = logConf.Sampling
+			}
+			// The writer itself is wrapped rather than logConf, so that its Sync
+			// method, if any, is called by Logger.Sync.
+			ws := zapcore.AddSync(logConf.Writer)
+			var async *AsyncWriter
+			if logConf.Async != nil {
+				async = NewAsyncWriter(ws, *logConf.Async)
+				asyncWriters = append(asyncWriters, async)
+				ws = async
+			}
+			limitedCore, rateLimitCloser := logConf.RateLimit.newCore(enc, ws, lvlRange)
+			childCore, samplingCloser := sampling.wrap(red.wrap(limitedCore))
+			childCores = append(childCores, newRoutedCore(r, i, childCore))
+			// The cores must be closed before the writers they write to, and
+			// the async writer before the writer it writes to. The sampling
+			// closer writes to the rate limited core, so it goes first.
+			for _, c := range []io.Closer{samplingCloser, rateLimitCloser} {
+				if c != nil {
+					closers = append(closers, c)
+				}
 			}
-			childCores = append(childCores, childCore)
+			if async != nil {
+				closers = appendCloser(closers, async)
+			}
+			closers = appendCloser(closers, logConf.Writer)
+			sinks = append(sinks, Sink{Name: fileSinkName(i), LoggerName: logConf.LoggerName, LoggerNames: logConf.LoggerNames, Level: lvlRange, route: i, Async: async})
 		}
 	}
 	if len(Errs) > 0 {
+		for _, async := range asyncWriters {
+			async.Close()
+		}
 		var buf bytes.Buffer
 		buf.WriteString("logger: errors initialising logs,")
 		for _, err := range Errs {
@@ -109,16 +131,10 @@ func NewWithConfig(c LogConfig) (*Logger, error) {
 	}
 	core := zapcore.NewTee(childCores...)
 	logger := zap.New(core, options...).Sugar()
-	zl := &Logger{zaplog: logger}
+	zl := &Logger{zaplog: logger, sinks: sinks, closers: closers, router: r}
 	defer func() {
-		innerErr := logger.Sync()
+		innerErr := zl.Sync()
 		if innerErr != nil {
-			var pathErr *os.PathError
-			if errors.As(innerErr, &pathErr) && strings.HasPrefix(pathErr.Path, "/dev/std") {
-				// Ignore logging any sync related errors for logging to
-				// /dev/std* as this may pollute logging to console.
-				return
-			}
 			// only log other errors to debug. the logger should still be usable
 			zl.Debug("logger syncing error", "syncerror", innerErr.Error())
 		}
@@ -134,6 +150,13 @@ func NewConsole() *Logger {
 	return l
 }
 
+// NewDiscard returns a Logger that discards all entries. Unlike a logger
+// without any sinks, it does not capture callers. Level semantics are kept,
+// so Panic and Panicf still panic, while Fatal and Fatalf still exit.
+func NewDiscard() *Logger {
+	return &Logger{zaplog: zap.New(zapcore.NewNopCore()).Sugar()}
+}
+
 // NewDefault creates 2 log files with max backups that rotates every day
 // at 12am.
 func NewDefault(logDir string, backups int, logConsole bool) *Logger {
@@ -222,6 +245,20 @@ func (l *Logger) Errorf(template string, args ...interface{}) {
 	l.zaplog.Errorf(template, args...)
 }
 
+func (l *Logger) DPanic(msg string, keysAndValues ...interface{}) {
+	if l == nil {
+		return
+	}
+	l.zaplog.DPanicw(msg, keysAndValues...)
+}
+
+func (l *Logger) DPanicf(template string, args ...interface{}) {
+	if l == nil {
+		return
+	}
+	l.zaplog.DPanicf(template, args...)
+}
+
 func (l *Logger) Panicf(template string, args ...interface{}) {
 	if l == nil {
 		panic(errors.New(fmt.Sprintf(template, args...)))
@@ -254,63 +291,221 @@ func (l *Logger) Fatalf(template string, args ...interface{}) {
 	l.zaplog.Fatalf(template, args...)
 }
 
+func (l *Logger) Log(level Level, msg string, keysAndValues ...interface{}) {
+	if l == nil {
+		switch level {
+		case PanicLevel:
+			l.Panic(msg, keysAndValues...)
+		case FatalLevel:
+			l.Fatal(msg, keysAndValues...)
+		}
+		return
+	}
+	// The sugared logger is called directly so that the caller is the same as
+	// for the level's own method.
+	switch level {
+	case DebugLevel:
+		l.zaplog.Debugw(msg, keysAndValues...)
+	case InfoLevel:
+		l.zaplog.Infow(msg, keysAndValues...)
+	case WarnLevel:
+		l.zaplog.Warnw(msg, keysAndValues...)
+	case ErrorLevel:
+		l.zaplog.Errorw(msg, keysAndValues...)
+	case DPanicLevel:
+		l.zaplog.DPanicw(msg, keysAndValues...)
+	case PanicLevel:
+		l.zaplog.Panicw(msg, keysAndValues...)
+	case FatalLevel:
+		l.zaplog.Fatalw(msg, keysAndValues...)
+	default:
+		// Enabled is checked first, as Desugar copies the logger.
+		if !l.Enabled(level) {
+			return
+		}
+		logger := l.zaplog.Desugar()
+		if len(keysAndValues) > 0 {
+			// Custom levels have no method on the sugared logger, so the
+			// key-value pairs are added via With for it to handle malformed
+			// pairs the same way as for the standard levels.
+			logger = l.zaplog.With(keysAndValues...).Desugar()
+		}
+		if ce := logger.Check(zapcore.Level(level), msg); ce != nil {
+			ce.Write()
+		}
+	}
+}
+
+func (l *Logger) Logf(level Level, template string, args ...interface{}) {
+	if l == nil {
+		switch level {
+		case PanicLevel:
+			l.Panicf(template, args...)
+		case FatalLevel:
+			l.Fatalf(template, args...)
+		}
+		return
+	}
+	switch level {
+	case DebugLevel:
+		l.zaplog.Debugf(template, args...)
+	case InfoLevel:
+		l.zaplog.Infof(template, args...)
+	case WarnLevel:
+		l.zaplog.Warnf(template, args...)
+	case ErrorLevel:
+		l.zaplog.Errorf(template, args...)
+	case DPanicLevel:
+		l.zaplog.DPanicf(template, args...)
+	case PanicLevel:
+		l.zaplog.Panicf(template, args...)
+	case FatalLevel:
+		l.zaplog.Fatalf(template, args...)
+	default:
+		// Enabled is checked first, as Desugar copies the logger.
+		if !l.Enabled(level) {
+			return
+		}
+		if ce := l.zaplog.Desugar().Check(zapcore.Level(level), fmt.Sprintf(template, args...)); ce != nil {
+			ce.Write()
+		}
+	}
+}
+
 func (l *Logger) With(args ...interface{}) iface.Logger {
+	if l == nil {
+		return l
+	}
 	newLogger := l.zaplog.With(args...)
-	return &Logger{zaplog: newLogger}
+	return l.withZap(newLogger)
+}
+
+func (l *Logger) WithContext(ctx context.Context) iface.Logger {
+	kvs := iface.ExtractContext(ctx)
+	if len(kvs) == 0 {
+		return l
+	}
+	return l.With(kvs...)
 }
 
 func (l *Logger) Named(loggerName string) iface.Logger {
-	newLogger := l.zaplog.Named(loggerName)
-	return &Logger{zaplog: newLogger}
+	if l == nil {
+		return l
+	}
+	newLogger := l.withZap(l.zaplog.Named(loggerName))
+	// Named joins names the same way as zap.
+	if l.name == "" {
+		newLogger.name = loggerName
+	} else if loggerName != "" {
+		newLogger.name = l.name + "." + loggerName
+	}
+	return newLogger
 }
 
 func (l *Logger) CallSkip(skips int) iface.Logger {
+	if l == nil {
+		return l
+	}
 	newLogger := l.zaplog.WithOptions(zap.AddCallerSkip(skips))
-	return &Logger{zaplog: newLogger}
+	return l.withZap(newLogger)
+}
+
+// Enabled returns true if any sink logs entries at level from the logger,
+// taking into account the level ranges of the sinks, the routing of logger
+// names and LogConfig.LoggerLevels. Entries may still be dropped by sampling
+// or rate limits.
+func (l *Logger) Enabled(level Level) bool {
+	if l == nil {
+		return false
+	}
+	ent := zapcore.Entry{Level: zapcore.Level(level), LoggerName: l.name}
+	for _, s := range l.sinks {
+		if s.Level.Enabled(ent.Level) && l.router.accepts(ent, s.route) {
+			return true
+		}
+	}
+	return false
 }
 
-// exclusiveCore is a wrapper around zapcore.Core. It takes a list of logger
-// names and check entries depending on the include parameter:
-//   - If include==true: check entries if entry's logger name is in the
-//     list of logger names passed in
-//   - else: check entries if entry's logger name is not in the list of logger
-//     name passed in
-type exclusiveCore struct {
-	loggerNames []string
-	include     bool
-	zapcore.Core
+// Sync flushes any buffered log entries of all sinks. Errors from syncing
+// stdout and stderr are ignored, as syncing them is not supported on all
+// platforms.
+func (l *Logger) Sync() error {
+	if l == nil {
+		return nil
+	}
+	var errs []error
+	for _, err := range multierr.Errors(l.zaplog.Sync()) {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) && strings.HasPrefix(pathErr.Path, "/dev/std") {
+			continue
+		}
+		errs = append(errs, err)
+	}
+	return multierr.Combine(errs...)
 }
 
-func newExclusiveCore(loggerNames []string, include bool, core zapcore.Core) zapcore.Core {
-	// loggerNames are sorted only once upon creation of a new core.
-	sort.Strings(loggerNames)
-	return &exclusiveCore{
-		loggerNames: loggerNames,
-		include:     include,
-		Core:        core,
+// Close flushes any buffered log entries and closes every LogFileConfig
+// writer that implements io.Closer. As the writers are shared, this affects
+// the Logger it was derived from as well as all loggers derived from it.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
 	}
+	err := l.Sync()
+	for _, c := range l.closers {
+		err = multierr.Append(err, c.Close())
+	}
+	return err
 }
 
-func (c *exclusiveCore) With(fields []zapcore.Field) zapcore.Core {
-	return &exclusiveCore{
-		loggerNames: c.loggerNames,
-		include:     c.include,
-		Core:        c.Core.With(fields),
+// appendCloser appends w to closers if it implements io.Closer and is not
+// already in closers. The standard streams are never closed.
+func appendCloser(closers []io.Closer, w io.Writer) []io.Closer {
+	c, ok := w.(io.Closer)
+	if !ok || w == os.Stdout || w == os.Stderr {
+		return closers
+	}
+	if !reflect.TypeOf(c).Comparable() {
+		// Comparing values of an uncomparable type panics, and such writers
+		// cannot be shared by value anyway.
+		return append(closers, c)
 	}
+	for _, existing := range closers {
+		if existing == c {
+			return closers
+		}
+	}
+	return append(closers, c)
+}
+
+// withZap returns a copy of the logger that logs via zaplog instead.
+func (l *Logger) withZap(zaplog *zap.SugaredLogger) *Logger {
+	newLogger := *l
+	newLogger.zaplog = zaplog
+	return &newLogger
+}
+
+// Sinks returns the sinks that the logger writes to. The console sink, if
+// configured, comes first followed by the file sinks in the order of
+// LogConfig.LogFileConfigs. File sinks without a writer are left out.
+func (l *Logger) Sinks() []Sink {
+	if l == nil {
+		return nil
+	}
+	return append([]Sink(nil), l.sinks...)
 }
 
-// Check overrides the underlying zapcore.Core implementation by having a check on whether to include
-// based on the entry's logger name.
-// nolint // to satisfy zapcore.Core interface
-func (c *exclusiveCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
-	i := sort.SearchStrings(c.loggerNames, ent.LoggerName)
-	checkCond := i < len(c.loggerNames) && c.loggerNames[i] == ent.LoggerName
-	if !c.include {
-		// if not include ==> exclude if found in logger names.
-		checkCond = !checkCond
+// Sink returns the sink with the given name. See Sink.Name for how sinks are
+// named.
+func (l *Logger) Sink(name string) (Sink, bool) {
+	if l == nil {
+		return Sink{}, false
 	}
-	if checkCond {
-		return c.Core.Check(ent, ce)
+	for _, s := range l.sinks {
+		if s.Name == name {
+			return s, true
+		}
 	}
-	return ce
+	return Sink{}, false
 }
@@ -0,0 +1,99 @@
+package zaplogi
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// syncBuffer is a bytes.Buffer that counts the calls to Sync.
+type syncBuffer struct {
+	bytes.Buffer
+	syncs int
+}
+
+func (b *syncBuffer) Sync() error {
+	b.syncs++
+	return nil
+}
+
+func TestLoggerSyncSyncsWriters(t *testing.T) {
+	w := &syncBuffer{}
+	l, err := NewWithConfig(LogConfig{
+		LogFileConfigs: []LogFileConfig{{LogRange: [2]Level{InfoLevel, MaxLevel}, Writer: w}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := w.syncs
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if w.syncs != before+1 {
+		t.Errorf("writer synced %d times by Logger.Sync, want 1", w.syncs-before)
+	}
+}
+
+// uncomparableCloser is an io.WriteCloser of an uncomparable type.
+type uncomparableCloser struct {
+	closed []bool
+}
+
+func (c uncomparableCloser) Write(p []byte) (int, error) { return len(p), nil }
+
+func (c uncomparableCloser) Close() error {
+	c.closed[0] = true
+	return nil
+}
+
+func TestAppendCloserUncomparable(t *testing.T) {
+	a := uncomparableCloser{closed: make([]bool, 1)}
+	b := uncomparableCloser{closed: make([]bool, 1)}
+	closers := appendCloser(nil, a)
+	closers = appendCloser(closers, b)
+	if len(closers) != 2 {
+		t.Fatalf("got %d closers, want 2", len(closers))
+	}
+	f := &File{}
+	closers = appendCloser(closers, f)
+	closers = appendCloser(closers, f)
+	if len(closers) != 3 {
+		t.Errorf("got %d closers after appending the same file twice, want 3", len(closers))
+	}
+}
+
+func TestLogMalformedKeysAndValues(t *testing.T) {
+	for _, level := range []Level{InfoLevel, NoticeLevel} {
+		var buf syncBuffer
+		l, err := NewWithConfig(LogConfig{
+			EncoderConfig:  EncoderConfig{TimeKey: "-"},
+			LogFileConfigs: []LogFileConfig{{LogRange: [2]Level{MinLevel, MaxLevel}, Writer: &buf}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.Log(level, "msg", "key", "value", "dangling")
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		// The sugared logger reports the key without a value in a separate
+		// entry for all levels.
+		if len(lines) != 2 || !strings.Contains(lines[0], "Ignored key without a value.") || !strings.Contains(lines[1], "msg") {
+			t.Errorf("Log(%v) logged %q, want a report of the dangling key and the entry", level, lines)
+		}
+	}
+}
+
+func TestLogDisabledCustomLevelAllocs(t *testing.T) {
+	l, err := NewWithConfig(LogConfig{
+		LogFileConfigs: []LogFileConfig{{LogRange: [2]Level{InfoLevel, MaxLevel}, Writer: &syncBuffer{}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		l.Log(TraceLevel, "msg", "key", "value")
+		l.Logf(TraceLevel, "template %s", "arg")
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations logging at a disabled custom level, want 0", allocs)
+	}
+}